@@ -2,8 +2,9 @@
 // Use of this source code is governed by the MIT license
 // that can be found in the LICENSE.md file.
 
-// Package auth implements provides a helper function for extracting the username and password
-// from a Basic Authorization header as specified by (RFC7617).
+// Package auth implements provides helper functions for extracting the username and password
+// from a Basic Authorization header as specified by (RFC7617), and the token from a Bearer Authorization header as
+// specified by (RFC6750).
 package auth
 
 import (
@@ -19,6 +20,9 @@ const (
 
 	// credentialsArrayExpectedSize is the epected size of the Basic Auth header after being decoded and split.
 	credentialsArrayExpectedSize = 2
+
+	// bearerAuthHeaderPrefix as specified by (RFC6750).
+	bearerAuthHeaderPrefix = "Bearer "
 )
 
 // ExtractCredentials attempts to extract and decode the Basic Authorization header in a set of request headers,
@@ -51,3 +55,21 @@ func ExtractCredentials(headers map[string]string) (handle string, password stri
 	// If the authorization header was not found, exit with an error.
 	return "", "", errors.New("Authorization header not found")
 }
+
+// ExtractBearerToken attempts to extract the token from the Bearer Authorization header in a set of request headers,
+// as specified by (RFC6750).
+func ExtractBearerToken(headers map[string]string) (token string, err error) {
+
+	// Check for the existence of the authorization header.
+	authHeader, ok := headers["Authorization"]
+	if !ok {
+		return "", errors.New("Authorization header not found")
+	}
+
+	// The header must be the prefix "Bearer " followed by a non-empty token.
+	if !strings.HasPrefix(authHeader, bearerAuthHeaderPrefix) || len(authHeader) == len(bearerAuthHeaderPrefix) {
+		return "", errors.New("Authorization header format invalid")
+	}
+
+	return strings.TrimPrefix(authHeader, bearerAuthHeaderPrefix), nil
+}
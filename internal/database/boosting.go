@@ -0,0 +1,165 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+)
+
+// Get the number of rows in the pairings table between the two specified players (in either order) that were created within the specified number of hours, along with the number won by each player, the number drawn, and the number shorter than the specified number of seconds.
+var psGetPairingStats = fmt.Sprintf("SELECT COUNT(*), COALESCE(SUM(`winner` = ?), 0), COALESCE(SUM(`winner` = ?), 0), COALESCE(SUM(`winner` = 0), 0), COALESCE(SUM(`duration` < ?), 0) FROM `%v`.`%v` WHERE ((`player1` = ? AND `player2` = ?) OR (`player1` = ? AND `player2` = ?)) AND `created` > DATE_SUB(NOW(), INTERVAL ? HOUR);", dbname, dbtablePairings)
+
+// Insert a new row into the pairings table, setting "player1", "player2", "winner", "duration", and "multiplier" with the specified values.
+var psAddPairing = fmt.Sprintf("INSERT INTO `%v`.`%v` (`player1`, `player2`, `winner`, `duration`, `multiplier`) VALUES (?, ?, ?, ?, ?);", dbname, dbtablePairings)
+
+// Insert a new row into the review queue table, setting "player", "opponent", "reason", and "details" with the specified values, unless there is already an unresolved row with the same player, opponent, and reason.
+var psAddReviewFlag = fmt.Sprintf("INSERT INTO `%[1]v`.`%[2]v` (`player`, `opponent`, `reason`, `details`) SELECT ?, ?, ?, ? FROM DUAL WHERE NOT EXISTS (SELECT * FROM `%[1]v`.`%[2]v` WHERE `player` = ? AND `opponent` = ? AND `reason` = ? AND `resolved` IS NULL);", dbname, dbtableReviewQueue)
+
+// Get the "id", "reason", "details", and "created" columns, and the handle and public ID of the player and opponent, for every unresolved row in the review queue table, oldest first.
+var psGetReviewQueue = fmt.Sprintf("SELECT `r`.`id`, `p`.`handle`, `p`.`public_id`, `o`.`handle`, `o`.`public_id`, `r`.`reason`, `r`.`details`, `r`.`created` FROM `%[1]v`.`%[2]v` `r` JOIN `%[1]v`.`%[3]v` `p` on `p`.`id` = `r`.`player` JOIN `%[1]v`.`%[3]v` `o` on `o`.`id` = `r`.`opponent` WHERE `r`.`resolved` IS NULL ORDER BY `r`.`created`, `r`.`id`;", dbname, dbtableReviewQueue, dbtableUsers)
+
+// Set the "resolved" column to the current time, and "resolved_by" to the specified value, for the unresolved row in the review queue table with the specified ID.
+var psResolveReviewFlag = fmt.Sprintf("UPDATE `%v`.`%v` SET `resolved` = NOW(), `resolved_by` = ? WHERE `id` = ? AND `resolved` IS NULL;", dbname, dbtableReviewQueue)
+
+// GetPairingStats returns the stats for the recent matches between the two specified players, within the window set
+// by settings.RepeatWindow. The wins are from the point of view of the players in the order that they were specified.
+func GetPairingStats(player1DatabaseID uint64, player2DatabaseID uint64) (stats types.PairingStats, err error) {
+
+	// Prepare a statement that will get the stats for the pair. Exit early on error.
+	statement, err := db.Prepare(psGetPairingStats)
+	if err != nil {
+		return stats, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the pairings table, and scan the stats into the return variable. Exit early on error.
+	err = statement.QueryRow(player1DatabaseID, player2DatabaseID, settings.BoostingShortMatch, player1DatabaseID, player2DatabaseID, player2DatabaseID, player1DatabaseID, settings.RepeatWindow).Scan(&stats.Matches, &stats.Player1Wins, &stats.Player2Wins, &stats.Draws, &stats.Short)
+	if err != nil {
+		return stats, err
+	}
+
+	return stats, nil
+}
+
+// addPairing records a match between the two specified players, and adds any review flags raised by the match to the
+// review queue, as part of the specified transaction. The winner should be the database ID of the winner, or 0 for a
+// draw.
+func addPairing(transaction *sql.Tx, player1DatabaseID uint64, player2DatabaseID uint64, winnerDatabaseID uint64, pairing types.Pairing) (err error) {
+
+	// Prepare a statement that will add the pairing. Exit early on error.
+	statement, err := transaction.Prepare(psAddPairing)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, adding the pairing. Exit early on error.
+	_, err = statement.Exec(player1DatabaseID, player2DatabaseID, winnerDatabaseID, pairing.Duration, pairing.Multiplier)
+	if err != nil {
+		return err
+	}
+
+	// Exit early if there are no flags to add.
+	if len(pairing.Flags) == 0 {
+		return nil
+	}
+
+	// Prepare a statement that will add a review flag. Exit early on error.
+	statement, err = transaction.Prepare(psAddReviewFlag)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Add each flag, unless the same flag is already waiting for review. Exit early on error.
+	for _, flag := range pairing.Flags {
+		_, err = statement.Exec(flag.Player, flag.Opponent, flag.Reason, flag.Details, flag.Player, flag.Opponent, flag.Reason)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetReviewQueue returns every unresolved flag in the review queue, oldest first.
+func GetReviewQueue() (queue types.ReviewQueueResponsePayload, err error) {
+
+	// Prepare a statement that will get the review queue. Exit early on error.
+	statement, err := db.Prepare(psGetReviewQueue)
+	if err != nil {
+		return queue, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the review queue table. Exit early on error.
+	rows, err := statement.Query()
+	if err != nil {
+		return queue, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all the rows, scanning each into a new entry. Exit early on error.
+	queue.Flags = make([]types.ReviewQueueEntry, 0)
+	for rows.Next() {
+		entry := types.ReviewQueueEntry{}
+		err = rows.Scan(&entry.ID, &entry.PlayerHandle, &entry.PlayerPublicID, &entry.OpponentHandle, &entry.OpponentPublicID, &entry.Reason, &entry.Details, &entry.Created)
+		if err != nil {
+			return queue, err
+		}
+
+		queue.Flags = append(queue.Flags, entry)
+	}
+
+	return queue, rows.Err()
+}
+
+// ResolveReviewFlag removes the flag with the specified ID from the review queue, recording the moderator that
+// resolved it.
+func ResolveReviewFlag(id uint64, resolvedBy uint64) (err error) {
+
+	// Prepare a statement that will resolve the flag. Exit early on error.
+	statement, err := db.Prepare(psResolveReviewFlag)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, resolving the flag. Exit early on error.
+	result, err := statement.Exec(resolvedBy, id)
+	if err != nil {
+		return err
+	}
+
+	// If no rows were affected, the flag does not exist, or has already been resolved.
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("Review flag not found")
+	}
+
+	return nil
+}
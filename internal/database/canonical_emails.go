@@ -0,0 +1,143 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/internal/validation"
+)
+
+// Get the "id" and "email" columns for every row in the users table, ordered by ID.
+var psGetAllEmails = fmt.Sprintf("SELECT `id`, `email` FROM `%v`.`%v` ORDER BY `id`;", dbname, dbtableUsers)
+
+// Update the "email_canonical" column for the row in the users table with the specified database ID.
+var psUpdateCanonicalEmail = fmt.Sprintf("UPDATE `%v`.`%v` SET `email_canonical` = ? WHERE `id` = ?;", dbname, dbtableUsers)
+
+// GetCanonicalEmails returns the canonical form of the email address for every user, by database ID, along with every
+// group of users whose email addresses share the same canonical form.
+func GetCanonicalEmails() (canonical map[uint64]string, collisions []types.CanonicalEmailCollision, err error) {
+
+	// Prepare a statement that will get the ID and email for every user. Exit early on error.
+	statement, err := db.Prepare(psGetAllEmails)
+	if err != nil {
+		return canonical, collisions, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the users table for all users.
+	rows, err := statement.Query()
+	if err != nil {
+		return canonical, collisions, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Group every user by the canonical form of their email. The order in which canonical forms are first seen is
+	// stored as well, so that the returned collisions are in a stable order (by lowest database ID).
+	canonical = make(map[uint64]string)
+	groups := make(map[string]*types.CanonicalEmailCollision)
+	order := make([]string, 0)
+	for rows.Next() {
+
+		// Scan the current row into temporary variables. Exit early on error.
+		var databaseID uint64
+		var email string
+		err = rows.Scan(&databaseID, &email)
+		if err != nil {
+			return canonical, collisions, err
+		}
+
+		// Add the user to the group for their canonical email, creating the group if it does not exist yet.
+		key := validation.CanonicalEmail(email)
+		group, ok := groups[key]
+		if !ok {
+			group = &types.CanonicalEmailCollision{Canonical: key}
+			groups[key] = group
+			order = append(order, key)
+		}
+
+		group.DatabaseIDs = append(group.DatabaseIDs, databaseID)
+		group.Emails = append(group.Emails, email)
+		canonical[databaseID] = key
+	}
+
+	// Check for any errors that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		return canonical, collisions, err
+	}
+
+	// Any group with more than one user is a collision.
+	collisions = make([]types.CanonicalEmailCollision, 0)
+	for _, key := range order {
+		if len(groups[key].DatabaseIDs) > 1 {
+			collisions = append(collisions, *groups[key])
+		}
+	}
+
+	return canonical, collisions, nil
+}
+
+// BackfillCanonicalEmails sets the "email_canonical" column for every user, based on their current email. Where more
+// than one existing user shares the same canonical email, only the oldest account (the lowest database ID) is given
+// it, and the rest are left NULL - existing accounts are not locked out, but no new account can use that inbox.
+func BackfillCanonicalEmails() (updated int, skipped int, err error) {
+
+	// Determine the canonical email for every user, and which users share one.
+	canonical, collisions, err := GetCanonicalEmails()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Remove every user other than the oldest in each collision.
+	for _, collision := range collisions {
+		for _, databaseID := range collision.DatabaseIDs[1:] {
+			delete(canonical, databaseID)
+			skipped++
+		}
+	}
+
+	// As this database interaction has multiple steps, begin a transaction so that either every row is updated, or none are.
+	transaction, err := db.Begin()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will update the canonical email for a single user. Exit early on error.
+	statement, err := transaction.Prepare(psUpdateCanonicalEmail)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Write the canonical email for each user. Exit early on error.
+	for databaseID, email := range canonical {
+		_, err = statement.Exec(email, databaseID)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		updated++
+	}
+
+	// Commit the transaction, essentially finalizing all the changes that were just made. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return updated, skipped, nil
+}
@@ -17,6 +17,7 @@ import (
 
 	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/internal/validation"
 	"github.com/6a/blade-ii-api/pkg/elo"
 	"github.com/6a/blade-ii-game-server/pkg/rid"
 
@@ -54,17 +55,65 @@ var (
 
 	// The following values are simply read from the environment variables, to be used throughout this package
 	// in order to interact with the database.
-	dbuser          = os.Getenv("db_user")
-	dbpass          = os.Getenv("db_pass")
-	dburl           = os.Getenv("db_url")
-	dbport          = os.Getenv("db_port")
-	dbname          = os.Getenv("db_name")
-	dbtableUsers    = os.Getenv("db_table_users")
-	dbtableProfiles = os.Getenv("db_table_profiles")
-	dbtableMatches  = os.Getenv("db_table_matches")
-	dbtableTokens   = os.Getenv("db_table_tokens")
+	dbuser                = os.Getenv("db_user")
+	dbpass                = os.Getenv("db_pass")
+	dburl                 = os.Getenv("db_url")
+	dbport                = os.Getenv("db_port")
+	dbname                = os.Getenv("db_name")
+	dbtableUsers          = os.Getenv("db_table_users")
+	dbtableProfiles       = os.Getenv("db_table_profiles")
+	dbtableMatches        = os.Getenv("db_table_matches")
+	dbtableTokens         = os.Getenv("db_table_tokens")
+	dbtablePrivilegeAudit = os.Getenv("db_table_privilege_audit")
+	dbtableReserved       = os.Getenv("db_table_reserved_handles")
+	dbtableProfanity      = os.Getenv("db_table_profanity_terms")
+	dbtableRateLimits     = os.Getenv("db_table_rate_limits")
+	dbtableBlockedDomains = os.Getenv("db_table_blocked_email_domains")
+	dbtableRatingHistory  = os.Getenv("db_table_rating_history")
+	dbtableSeasons        = os.Getenv("db_table_seasons")
+	dbtableStandings      = os.Getenv("db_table_season_standings")
+	dbtablePairings       = os.Getenv("db_table_pairings")
+	dbtableReviewQueue    = os.Getenv("db_table_review_queue")
+	dbtableQueueRatings   = os.Getenv("db_table_queue_ratings")
+	dbtableSubmissions    = os.Getenv("db_table_match_submissions")
 )
 
+// leaderboardsCondition returns an extra condition for the leaderboards queries, which excludes accounts that have not
+// confirmed their email address, and players whose rating is still provisional, unless the settings allow them on the
+// leaderboards. The users and profiles tables must be aliased as "u" and "p".
+func leaderboardsCondition() (condition string) {
+	if !settings.UnconfirmedAccountsOnLeaderboards {
+		condition += " AND `u`.`email_confirmed` = 1"
+	}
+
+	if !settings.ProvisionalOnLeaderboards {
+		condition += fmt.Sprintf(" AND `p`.`wins` + `p`.`draws` + `p`.`losses` >= %v", settings.PlacementGames)
+	}
+
+	return condition
+}
+
+// provisional returns true if a player with the specified match stats still has a provisional rating.
+func provisional(wins uint32, draws uint32, losses uint32) bool {
+	return wins+draws+losses < settings.PlacementGames
+}
+
+// tierPolicy places players that have no stored tier into a tier, based on their mmr. The buffers are not needed, as
+// players are only moved between tiers by the routes.
+var tierPolicy = elo.TierPolicy{Tiers: settings.Tiers}
+
+// placement returns the specified stored tier and division, or if the player has not been placed (the tier is empty),
+// the tier and division for the specified mmr.
+func placement(tier string, division uint8, mmr int16) (string, uint8) {
+	if tier != "" {
+		return tier, division
+	}
+
+	p := tierPolicy.Place(float64(mmr))
+
+	return p.Tier, p.Division
+}
+
 // Privilege levels for accounts within the database.
 const (
 	UserPrivilege        uint8 = 0
@@ -72,8 +121,8 @@ const (
 	ServerAdminPrivilege uint8 = 2
 )
 
-// Insert a new row into the users table, setting "public_id", "handle", "email", and "salted_hash" with the specified values.
-var psCreateAccount = fmt.Sprintf("INSERT INTO `%v`.`%v` (`public_id`, `handle`, `email`, `salted_hash`) VALUES (?, ?, ?, ?);", dbname, dbtableUsers)
+// Insert a new row into the users table, setting "public_id", "handle", "handle_key", "email", "email_canonical", and "salted_hash" with the specified values.
+var psCreateAccount = fmt.Sprintf("INSERT INTO `%v`.`%v` (`public_id`, `handle`, `handle_key`, `email`, `email_canonical`, `salted_hash`) VALUES (?, ?, ?, ?, ?, ?);", dbname, dbtableUsers)
 
 // Update a row in the tokens table, setting the value and expiry for the specified token. Contains strings that should be replaced with the token column name
 // and token expiry column name - use createAddTokenPS().
@@ -82,17 +131,20 @@ var psAddTokenWithReplacers = fmt.Sprintf("UPDATE `%v`.`%v` SET `repl_1` = ?, `r
 // Get the "id" column from the row in the users table with the specified public ID.
 var psGetDBIDFromPID = fmt.Sprintf("SELECT `id` FROM `%v`.`%v` WHERE `public_id` = ?;", dbname, dbtableUsers)
 
-// Return a row with a value of either true of false, based on whether a row exists in the users table with the specified handle.
-var psCheckName = fmt.Sprintf("SELECT EXISTS(SELECT * FROM `%v`.`%v` WHERE `handle` = ?);", dbname, dbtableUsers)
+// Return a row with a value of either true of false, based on whether a row exists in the users table with the specified handle key.
+var psCheckName = fmt.Sprintf("SELECT EXISTS(SELECT * FROM `%v`.`%v` WHERE `handle_key` = ?);", dbname, dbtableUsers)
 
-// Get the "salted_hash", and "banned" column from the row in the users table with the specified handle.
-var psCheckAuth = fmt.Sprintf("SELECT `salted_hash`, `banned` FROM `%v`.`%v` WHERE `handle` = ?;", dbname, dbtableUsers)
+// Return a row with two values of either true or false, based on whether a row exists in the users table with the specified canonical email, and with the specified email.
+var psCheckEmail = fmt.Sprintf("SELECT EXISTS(SELECT * FROM `%v`.`%v` WHERE `email_canonical` = ?), EXISTS(SELECT * FROM `%v`.`%v` WHERE `email` = ?);", dbname, dbtableUsers, dbname, dbtableUsers)
 
-// Get the "id", and "public_id" columns from the row in the users table with the specified handle.
-var psGetIDs = fmt.Sprintf("SELECT `id`, `public_id` FROM `%v`.`%v` WHERE `handle` = ?;", dbname, dbtableUsers)
+// Get the "salted_hash", and "banned" column from the row in the users table with the specified handle key.
+var psCheckAuth = fmt.Sprintf("SELECT `salted_hash`, `banned` FROM `%v`.`%v` WHERE `handle_key` = ?;", dbname, dbtableUsers)
 
-// Get the "privilege" column from the row in the users table with the specified handle.
-var psGetPrivilege = fmt.Sprintf("SELECT `privilege` FROM `%v`.`%v` WHERE `handle` = ?;", dbname, dbtableUsers)
+// Get the "id", and "public_id" columns from the row in the users table with the specified handle key.
+var psGetIDs = fmt.Sprintf("SELECT `id`, `public_id` FROM `%v`.`%v` WHERE `handle_key` = ?;", dbname, dbtableUsers)
+
+// Get the "privilege" column from the row in the users table with the specified handle key.
+var psGetPrivilege = fmt.Sprintf("SELECT `privilege` FROM `%v`.`%v` WHERE `handle_key` = ?;", dbname, dbtableUsers)
 
 // Insert a new row into the tokens table, setting "id", "email_confirmation", and "email_confirmation_expiry" with the specified values.
 var psCreateTokenRowWithEmailToken = fmt.Sprintf("INSERT INTO `%v`.`%v` (`id`, `email_confirmation`, `email_confirmation_expiry`) VALUES (LAST_INSERT_ID(), ?, DATE_ADD(NOW(), INTERVAL ? HOUR));", dbname, dbtableTokens)
@@ -100,29 +152,32 @@ var psCreateTokenRowWithEmailToken = fmt.Sprintf("INSERT INTO `%v`.`%v` (`id`, `
 // Get the "auth", "auth_expiry", and "banned" columns from the row in the tokens table with the specified public ID, JOINED with the users table.
 var psGetAuthData = fmt.Sprintf("SELECT `t`.`auth`, `t`.`auth_expiry`, `u`.`banned` FROM `%[1]v`.`%[2]v` `t` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `t`.`id` WHERE `u`.`public_id` = ?;", dbname, dbtableTokens, dbtableUsers)
 
-// Get the "mmr", "wins", "draws", and "losses" columns from the row in the profiles table with the specified database ID.
-var psGetMatchStats = fmt.Sprintf("SELECT `mmr`, `wins`, `draws`, `losses` FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableProfiles)
+// Get the "mmr", "rating_deviation", "rating_volatility", "wins", "draws", "losses", "tier", and "division" columns from the row in the profiles table with the specified database ID. The tier is empty if the player has not been placed.
+var psGetMatchStats = fmt.Sprintf("SELECT `mmr`, `rating_deviation`, `rating_volatility`, `wins`, `draws`, `losses`, COALESCE(`tier`, ''), COALESCE(`division`, 0) FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableProfiles)
+
+// Update the "mmr", "rating_deviation", "rating_volatility", "wins", "draws", "losses", "tier", and "division" column for the row in the profiles table with the specified database ID, add the specified values to the "season_wins", "season_draws", and "season_losses" columns, and set the "last_ranked" column to the current time.
+var psUpdateMMR = fmt.Sprintf("UPDATE `%v`.`%v` SET `mmr` = ?, `rating_deviation` = ?, `rating_volatility` = ?, `wins` = ?, `draws` = ?, `losses` = ?, `season_wins` = `season_wins` + ?, `season_draws` = `season_draws` + ?, `season_losses` = `season_losses` + ?, `tier` = ?, `division` = ?, `last_ranked` = NOW() WHERE `id` = ?;", dbname, dbtableProfiles)
 
-// Update the "mmr", "wins", "draws", and "losses" column for the row in the profiles table with the specified database ID.
-var psUpdateMMR = fmt.Sprintf("UPDATE `%v`.`%v` SET `mmr` = ?, `wins` = ?, `draws` = ?, `losses` = ? WHERE `id` = ?;", dbname, dbtableProfiles)
+// Update the "queue" column (to the ranked queue), "playerN_mmr_before" columns (from each player's current mmr in the profiles table) and "playerN_mmr_after" columns (with the specified values) for the row in the matches table with the specified match ID and players.
+var psUpdateMatchRatings = fmt.Sprintf("UPDATE `%[1]v`.`%[2]v` `m` SET `m`.`queue` = '%[4]v', `m`.`player1_mmr_before` = (SELECT `mmr` FROM `%[1]v`.`%[3]v` WHERE `id` = `m`.`player1`), `m`.`player1_mmr_after` = ?, `m`.`player2_mmr_before` = (SELECT `mmr` FROM `%[1]v`.`%[3]v` WHERE `id` = `m`.`player2`), `m`.`player2_mmr_after` = ? WHERE `m`.`id` = ? AND `m`.`player1` = ? AND `m`.`player2` = ?;", dbname, dbtableMatches, dbtableProfiles, settings.QueueRanked)
 
-// Get the "avatar", "mmr", "wins", "draws", "losses", "winratio", "ranked_total", and "created" columns from the row in the profiles table with the specified database ID.
-var psGetProfile = fmt.Sprintf("SELECT `avatar`, `mmr`, `wins`, `draws`, `losses`, `winratio`, `ranked_total`, `created` FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableProfiles)
+// Get the "avatar", "mmr", "rating_deviation", "wins", "draws", "losses", "winratio", "ranked_total", "season_wins", "season_draws", "season_losses", "tier", "division", and "created" columns from the row in the profiles table with the specified database ID.
+var psGetProfile = fmt.Sprintf("SELECT `avatar`, `mmr`, `rating_deviation`, `wins`, `draws`, `losses`, `winratio`, `ranked_total`, `season_wins`, `season_draws`, `season_losses`, COALESCE(`tier`, ''), COALESCE(`division`, 0), `created` FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableProfiles)
 
-// Get the "avatar", "mmr", "wins", "draws", "losses", "winratio", "ranked_total" (as "total"), "public_id" (as "pid"), and a generated column "rank" for a range of results specified, ordered using the rank function based on the entire table. ID's 99 or less are excluded due to being reserved for admin accounts.
-var psGetLeaderboards = fmt.Sprintf("SELECT `t`.`handle`, `t`.`avatar`, `t`.`mmr`, `t`.`wins`, `t`.`draws`, `t`.`losses`, `t`.`winratio`, `t`.`total`, `t`.`pid`, RANK() OVER (ORDER BY `t`.`mmr` DESC, `t`.`winratio` DESC) AS `rank` FROM (SELECT `u`.`handle`, `p`.`avatar`, `p`.`mmr`, `p`.`wins`, `p`.`draws`, `p`.`losses`, `p`.`winratio`,`p`.`ranked_total` AS `total`, `p`.`public_id` AS `pid` FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100) AS t ORDER BY `rank` LIMIT ? OFFSET ?;", dbname, dbtableProfiles, dbtableUsers)
+// Get the "avatar", "mmr", "wins", "draws", "losses", "winratio", "ranked_total" (as "total"), "public_id" (as "pid"), "tier", "division", and a generated column "rank" for a range of results specified, ordered using the rank function based on the entire table. ID's 99 or less are excluded due to being reserved for admin accounts, as are accounts excluded by the leaderboards condition.
+var psGetLeaderboards = fmt.Sprintf("SELECT `t`.`handle`, `t`.`avatar`, `t`.`mmr`, `t`.`wins`, `t`.`draws`, `t`.`losses`, `t`.`winratio`, `t`.`total`, `t`.`pid`, COALESCE(`t`.`tier`, ''), COALESCE(`t`.`division`, 0), RANK() OVER (ORDER BY `t`.`mmr` DESC, `t`.`winratio` DESC) AS `rank` FROM (SELECT `u`.`handle`, `p`.`avatar`, `p`.`mmr`, `p`.`wins`, `p`.`draws`, `p`.`losses`, `p`.`winratio`,`p`.`ranked_total` AS `total`, `p`.`public_id` AS `pid`, `p`.`tier`, `p`.`division` FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100%[4]v) AS t ORDER BY `rank` LIMIT ? OFFSET ?;", dbname, dbtableProfiles, dbtableUsers, leaderboardsCondition())
 
-// Get the "avatar", "mmr", "wins", "draws", "losses", "winratio", "ranked_total" (as "total"), "public_id" (as "pid"), and a generated column "rank" for the row with the specified public ID, ordered using the rank function based on the entire table. ID's 99 or less are excluded due to being reserved for admin accounts.
-var psGetIndividualRank = fmt.Sprintf("SELECT * FROM (SELECT `t`.`handle`, `t`.`avatar`, `t`.`mmr`, `t`.`wins`, `t`.`draws`, `t`.`losses`, `t`.`winratio`, `t`.`total`, `t`.`pid`, RANK() OVER (ORDER BY `t`.`mmr` DESC, `t`.`winratio` DESC) AS `rank` FROM (SELECT `u`.`handle`, `p`.`avatar`, `p`.`mmr`, `p`.`wins`, `p`.`draws`, `p`.`losses`, `p`.`winratio`,`p`.`ranked_total` AS `total`, `p`.`public_id` AS `pid` FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100) AS t) AS rt WHERE `pid` = ?", dbname, dbtableProfiles, dbtableUsers)
+// Get the "avatar", "mmr", "wins", "draws", "losses", "winratio", "ranked_total" (as "total"), "public_id" (as "pid"), "tier", "division", and a generated column "rank" for the row with the specified public ID, ordered using the rank function based on the entire table. ID's 99 or less are excluded due to being reserved for admin accounts, as are accounts excluded by the leaderboards condition.
+var psGetIndividualRank = fmt.Sprintf("SELECT * FROM (SELECT `t`.`handle`, `t`.`avatar`, `t`.`mmr`, `t`.`wins`, `t`.`draws`, `t`.`losses`, `t`.`winratio`, `t`.`total`, `t`.`pid`, COALESCE(`t`.`tier`, ''), COALESCE(`t`.`division`, 0), RANK() OVER (ORDER BY `t`.`mmr` DESC, `t`.`winratio` DESC) AS `rank` FROM (SELECT `u`.`handle`, `p`.`avatar`, `p`.`mmr`, `p`.`wins`, `p`.`draws`, `p`.`losses`, `p`.`winratio`,`p`.`ranked_total` AS `total`, `p`.`public_id` AS `pid`, `p`.`tier`, `p`.`division` FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100%[4]v) AS t) AS rt WHERE `pid` = ?", dbname, dbtableProfiles, dbtableUsers, leaderboardsCondition())
 
-// Get the size of the leaderboards table a single row with a single column. ID's 99 or less are excluded due to being reserved for admin accounts.
-var psGetLeaderboardsCount = fmt.Sprintf("SELECT COUNT(*) FROM `%v`.`%v` WHERE `id` >= 100;", dbname, dbtableProfiles)
+// Get the size of the leaderboards table a single row with a single column. ID's 99 or less are excluded due to being reserved for admin accounts, as are accounts excluded by the leaderboards condition.
+var psGetLeaderboardsCount = fmt.Sprintf("SELECT COUNT(*) FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100%[4]v;", dbname, dbtableProfiles, dbtableUsers, leaderboardsCondition())
 
 // Update the "avatar" column for the row in the profiles table with the specified public ID.
 var psUpdateAvatar = fmt.Sprintf("UPDATE `%v`.`%v` SET `avatar` = ? WHERE `public_id` = ?;", dbname, dbtableProfiles)
 
-// Using multiple joins, get the "id" (the match ID), and then "handle" (as "playerNhandle"), and "public_id" (as "playerNpid") for player 1 and 2 respectively, followed by "winnerhandle" and "winnerpid" (from "handle" and "public_id" for the winner), and finally the "end" column, for all of the matches that the specified player took part in, ordered by end time and match ID, in descending order.
-var psGetMatchHistory = fmt.Sprintf("SELECT `m`.`id`, `p1`.`handle` as `player1handle`, `p1`.`public_id` as `player1pid`, `p2`.`handle` as `player2handle`, `p2`.`public_id` as `player2pid`, `w`.`handle` as `winnerhandle`, `w`.`public_id` as `winnerpid`, `m`.`end` FROM `%[1]v`.`%[2]v` `m` JOIN `%[1]v`.`%[3]v` `p1` on `p1`.`id` = `m`.`player1` JOIN `%[1]v`.`%[3]v` `p2` on `p2`.`id` = `m`.`player2` JOIN `%[1]v`.`%[3]v` on IF(`m`.`winner` != 0, `w`.`id` = `m`.`winner`, `w`.`id` = 10) WHERE ? IN(`player1`, `player2`) AND `phase` = 2 ORDER BY `end` DESC, `id` DESC;", dbname, dbtableMatches, dbtableUsers)
+// Using multiple joins, get the "id" (the match ID), and then "handle" (as "playerNhandle"), and "public_id" (as "playerNpid") for player 1 and 2 respectively, followed by "winnerhandle" and "winnerpid" (from "handle" and "public_id" for the winner), the "end" column, and finally the "queue", "playerN_mmr_before" and "playerN_mmr_after" columns, for all of the matches that the specified player took part in, ordered by end time and match ID, in descending order.
+var psGetMatchHistory = fmt.Sprintf("SELECT `m`.`id`, `p1`.`handle` as `player1handle`, `p1`.`public_id` as `player1pid`, `p2`.`handle` as `player2handle`, `p2`.`public_id` as `player2pid`, `w`.`handle` as `winnerhandle`, `w`.`public_id` as `winnerpid`, `m`.`end`, `m`.`queue`, `m`.`player1_mmr_before`, `m`.`player1_mmr_after`, `m`.`player2_mmr_before`, `m`.`player2_mmr_after` FROM `%[1]v`.`%[2]v` `m` JOIN `%[1]v`.`%[3]v` `p1` on `p1`.`id` = `m`.`player1` JOIN `%[1]v`.`%[3]v` `p2` on `p2`.`id` = `m`.`player2` JOIN `%[1]v`.`%[3]v` `w` on IF(`m`.`winner` != 0, `w`.`id` = `m`.`winner`, `w`.`id` = 10) WHERE ? IN(`player1`, `player2`) AND `phase` = 2 ORDER BY `end` DESC, `id` DESC;", dbname, dbtableMatches, dbtableUsers)
 
 // Init should be called at the start of the function. It opens a connection to the database
 // based on the parameters defined by environment variables, as specified by the EnvironmentVariables struct.
@@ -187,8 +242,9 @@ func CreateUser(handle string, email string, password string) (emailValidationTo
 	// Create a new UUID, to be used as the public ID for this user.
 	publicID := xid.New()
 
-	// Create the user account using the specified, and generated details. Exit early on error.
-	_, err = statement.Exec(publicID, handle, email, saltedhash)
+	// Create the user account using the specified, and generated details. The handle is stored as-is for display,
+	// alongside its canonical key which is used for uniqueness and lookup. Exit early on error.
+	_, err = statement.Exec(publicID, handle, validation.CanonicalHandle(handle), email, validation.CanonicalEmail(email), saltedhash)
 	if err != nil {
 		return "", err
 	}
@@ -256,10 +312,10 @@ func ValidateCredentials(handle string, password string) (err error) {
 	// Defer closing of the statement so that it is cleaned up properly when this function exits.
 	defer statement.Close()
 
-	// Query the database with the specified handle. Exit early on error.
+	// Query the database with the canonical key for the specified handle. Exit early on error.
 	var banned bool
 	var saltedHash string
-	err = statement.QueryRow(handle).Scan(&saltedHash, &banned)
+	err = statement.QueryRow(validation.CanonicalHandle(handle)).Scan(&saltedHash, &banned)
 	if err != nil {
 		return err
 	}
@@ -298,10 +354,10 @@ func GetIDs(handle string) (databaseID int, publicID string, err error) {
 	// Defer closing of the statement so that it is cleaned up properly when this function exits.
 	defer statement.Close()
 
-	// Query the users table row with the specified handle. Read the found columns into the return
+	// Query the users table row with the canonical key for the specified handle. Read the found columns into the return
 	// variables for this function.
 	// Exit early on error.
-	err = statement.QueryRow(handle).Scan(&databaseID, &publicID)
+	err = statement.QueryRow(validation.CanonicalHandle(handle)).Scan(&databaseID, &publicID)
 	if err != nil {
 		return -1, "", err
 	}
@@ -334,8 +390,12 @@ func SetToken(id int, t types.Token, token string, hoursValid int) (err error) {
 	return nil
 }
 
-// UpdateMatchStats updates the mmr for the two specified clients, as well as w/d/l stats.
-func UpdateMatchStats(client1DatabaseID uint64, client1MatchStats types.MatchStats, client2DatabaseID uint64, client2MatchStats types.MatchStats, winner elo.Player) (err error) {
+// UpdateMatchStats updates the mmr for the two specified clients, as well as w/d/l stats, and records the change in
+// rating for each client in the rating history. If a match ID is specified, the change is also recorded in the row for
+// the match, which must be between the two clients. The match is also recorded as a pairing between the two clients,
+// and any review flags raised by the match are added to the review queue. If a submission is specified, it is recorded
+// as processed, and the update fails if it already has been.
+func UpdateMatchStats(matchID *uint64, client1DatabaseID uint64, client1MatchStats types.MatchStats, client2DatabaseID uint64, client2MatchStats types.MatchStats, winner elo.Player, pairing types.Pairing, submission *types.MatchSubmission) (err error) {
 
 	// As this database interaction has multiple steps, begin a transaction to protect against race conditions.
 	transaction, err := db.Begin()
@@ -347,19 +407,40 @@ func UpdateMatchStats(client1DatabaseID uint64, client1MatchStats types.MatchSta
 	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
 	defer transaction.Rollback()
 
+	// If a submission was specified, record it first, so that nothing else is changed if it was already processed.
+	// Exit early on error.
+	if submission != nil {
+		err = addMatchSubmission(transaction, *submission)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If a match ID was specified, record each player's rating before and after the match in the matches table. This
+	// is done before the profiles are updated, as the ratings before the match are read from the profiles.
+	if matchID != nil {
+		err = updateMatchRatings(transaction, psUpdateMatchRatings, client1MatchStats.MMR, client2MatchStats.MMR, *matchID, client1DatabaseID, client2DatabaseID)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Update the match stats for player 1.
 
 	// Update wins, losses, or draws depending on the outcome of the match.
-	if winner == elo.Draw {
-		client1MatchStats.Draws++
-	} else if winner == elo.Player1 {
-		client1MatchStats.Wins++
-	} else {
-		client1MatchStats.Losses++
+	client1Win, client1Draw, client1Loss := matchOutcome(winner, elo.Player1)
+	client1MatchStats.Wins += client1Win
+	client1MatchStats.Draws += client1Draw
+	client1MatchStats.Losses += client1Loss
+
+	// Record the change in rating for player 1, before the profile is updated. Exit early on error.
+	err = addRatingHistory(transaction, client1DatabaseID, matchID, types.RatingChangeMatch, client1MatchStats)
+	if err != nil {
+		return err
 	}
 
 	// Prepare a statement that will update the row in the profiles table for the player 1. Exit early on error.
-	statement, err := db.Prepare(psUpdateMMR)
+	statement, err := transaction.Prepare(psUpdateMMR)
 	if err != nil {
 		return err
 	}
@@ -368,7 +449,7 @@ func UpdateMatchStats(client1DatabaseID uint64, client1MatchStats types.MatchSta
 	defer statement.Close()
 
 	// Query the database, updating the row in the profiles table with the player 1's database ID. Exit early on error.
-	_, err = statement.Exec(client1MatchStats.MMR, client1MatchStats.Wins, client1MatchStats.Draws, client1MatchStats.Losses, client1DatabaseID)
+	_, err = statement.Exec(client1MatchStats.MMR, client1MatchStats.Deviation, client1MatchStats.Volatility, client1MatchStats.Wins, client1MatchStats.Draws, client1MatchStats.Losses, client1Win, client1Draw, client1Loss, client1MatchStats.Tier, client1MatchStats.Division, client1DatabaseID)
 	if err != nil {
 		return err
 	}
@@ -376,16 +457,19 @@ func UpdateMatchStats(client1DatabaseID uint64, client1MatchStats types.MatchSta
 	// Update the match stats for player 2.
 
 	// Update wins, losses, or draws depending on the outcome of the match.
-	if winner == elo.Draw {
-		client2MatchStats.Draws++
-	} else if winner == elo.Player2 {
-		client2MatchStats.Wins++
-	} else {
-		client2MatchStats.Losses++
+	client2Win, client2Draw, client2Loss := matchOutcome(winner, elo.Player2)
+	client2MatchStats.Wins += client2Win
+	client2MatchStats.Draws += client2Draw
+	client2MatchStats.Losses += client2Loss
+
+	// Record the change in rating for player 2, before the profile is updated. Exit early on error.
+	err = addRatingHistory(transaction, client2DatabaseID, matchID, types.RatingChangeMatch, client2MatchStats)
+	if err != nil {
+		return err
 	}
 
 	// Prepare a statement that will update the row in the profiles table for the player 2. Exit early on error.
-	statement, err = db.Prepare(psUpdateMMR)
+	statement, err = transaction.Prepare(psUpdateMMR)
 	if err != nil {
 		return err
 	}
@@ -394,7 +478,20 @@ func UpdateMatchStats(client1DatabaseID uint64, client1MatchStats types.MatchSta
 	defer statement.Close()
 
 	// Query the database, updating the row in the profiles table with the player 1's database ID. Exit early on error.
-	_, err = statement.Exec(client2MatchStats.MMR, client2MatchStats.Wins, client2MatchStats.Draws, client2MatchStats.Losses, client2DatabaseID)
+	_, err = statement.Exec(client2MatchStats.MMR, client2MatchStats.Deviation, client2MatchStats.Volatility, client2MatchStats.Wins, client2MatchStats.Draws, client2MatchStats.Losses, client2Win, client2Draw, client2Loss, client2MatchStats.Tier, client2MatchStats.Division, client2DatabaseID)
+	if err != nil {
+		return err
+	}
+
+	// Record the pairing between the players, and any review flags. Exit early on error.
+	var winnerDatabaseID uint64
+	if winner == elo.Player1 {
+		winnerDatabaseID = client1DatabaseID
+	} else if winner == elo.Player2 {
+		winnerDatabaseID = client2DatabaseID
+	}
+
+	err = addPairing(transaction, client1DatabaseID, client2DatabaseID, winnerDatabaseID, pairing)
 	if err != nil {
 		return err
 	}
@@ -409,7 +506,52 @@ func UpdateMatchStats(client1DatabaseID uint64, client1MatchStats types.MatchSta
 	return nil
 }
 
-// GetMatchStats returns the match stats (mmr, w/d/l) for the specified client.
+// updateMatchRatings records each player's rating before and after a match in the matches table, as part of the
+// specified transaction, using the specified statement (which reads the ratings before the match from the ladder that
+// the match was rated in). Returns an error if there is no match with the match ID between the players.
+func updateMatchRatings(transaction *sql.Tx, query string, args ...interface{}) (err error) {
+
+	// Prepare a statement that will update the ratings for the match. Exit early on error.
+	statement, err := transaction.Prepare(query)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, updating the row in the matches table. Exit early on error.
+	result, err := statement.Exec(args...)
+	if err != nil {
+		return err
+	}
+
+	// If no rows were affected, there is no match with the specified ID between these players.
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("Match not found")
+	}
+
+	return nil
+}
+
+// matchOutcome returns 1 for whichever of a win, draw, or loss the outcome of a match was for the specified player, and 0
+// for the others.
+func matchOutcome(winner elo.Player, player elo.Player) (win uint32, draw uint32, loss uint32) {
+	if winner == elo.Draw {
+		return 0, 1, 0
+	} else if winner == player {
+		return 1, 0, 0
+	}
+
+	return 0, 0, 1
+}
+
+// GetMatchStats returns the match stats (mmr, rating deviation and volatility, w/d/l) for the specified client.
 func GetMatchStats(databaseID uint64) (matchStats types.MatchStats, err error) {
 
 	// Prepare a statement that will get the match stats for the specified user. Exit early on error.
@@ -423,7 +565,7 @@ func GetMatchStats(databaseID uint64) (matchStats types.MatchStats, err error) {
 
 	// Query the row in the profiles table for the specified user, and read the returned columns into the return
 	// variables of this function. Exit early on error.
-	err = statement.QueryRow(databaseID).Scan(&matchStats.MMR, &matchStats.Wins, &matchStats.Draws, &matchStats.Losses)
+	err = statement.QueryRow(databaseID).Scan(&matchStats.MMR, &matchStats.Deviation, &matchStats.Volatility, &matchStats.Wins, &matchStats.Draws, &matchStats.Losses, &matchStats.Tier, &matchStats.Division)
 	if err != nil {
 		return matchStats, err
 	}
@@ -465,10 +607,10 @@ func HasRequiredPrivilege(handle string, privilegeLevelToCheck uint8) (isPrivile
 	// Defer closing of the statement so that it is cleaned up properly when this function exits.
 	defer statement.Close()
 
-	// Query the row in the users database for the specified user, and scan the resulting columns value into
-	// a temporary value. Exit early on error.
+	// Query the row in the users database for the specified user (by canonical handle key), and scan the resulting
+	// columns value into a temporary value. Exit early on error.
 	var actualPrivilege uint8
-	err = statement.QueryRow(handle).Scan(&actualPrivilege)
+	err = statement.QueryRow(validation.CanonicalHandle(handle)).Scan(&actualPrivilege)
 	if err != nil {
 		return false, errors.New("The user specified in the auth header does not exist")
 	}
@@ -502,7 +644,8 @@ func GetDatabaseID(publicID string) (databaseID uint64, err error) {
 	return databaseID, err
 }
 
-// GetProfile returns profile data for the user with the specified databaseID.
+// GetProfile returns profile data for the user with the specified databaseID, with the rating and w/d/l from the ladder
+// for the ranked queue.
 func GetProfile(databaseID uint64) (profile types.ProfileResponsePayload, err error) {
 
 	// Prepare a statement that will get the profile data for the specified user. Exit early on error.
@@ -519,7 +662,7 @@ func GetProfile(databaseID uint64) (profile types.ProfileResponsePayload, err er
 	// Note the edge case for winratio - it is possible for this value to be null, so it is scanned into temporary
 	// float32 pointer.
 	var winRatio *float32 = nil
-	err = statement.QueryRow(databaseID).Scan(&profile.Avatar, &profile.MMR, &profile.Wins, &profile.Draws, &profile.Losses, &winRatio, &profile.RankedTotal, &profile.Created)
+	err = statement.QueryRow(databaseID).Scan(&profile.Avatar, &profile.MMR, &profile.Deviation, &profile.Wins, &profile.Draws, &profile.Losses, &winRatio, &profile.RankedTotal, &profile.SeasonWins, &profile.SeasonDraws, &profile.SeasonLosses, &profile.Tier, &profile.Division, &profile.Created)
 	if err != nil {
 		return profile, err
 	}
@@ -531,12 +674,17 @@ func GetProfile(databaseID uint64) (profile types.ProfileResponsePayload, err er
 		profile.WinRatio = *winRatio
 	}
 
+	profile.Queue = settings.QueueRanked
+	profile.Provisional = provisional(profile.Wins, profile.Draws, profile.Losses)
+	profile.Tier, profile.Division = placement(profile.Tier, profile.Division, profile.MMR)
+
 	return profile, err
 }
 
-// GetLeaderboards returns the leaderboards, starting at rank (start) returning (count) results, as well
-// as an extra blob containg the details for the user specified.
+// GetLeaderboards returns the leaderboards for the ranked queue, starting at rank (start) returning (count) results,
+// as well as an extra blob containg the details for the user specified.
 func GetLeaderboards(publicID string, start uint64, count uint64) (leaderboards types.LeaderboardResponsePayload, err error) {
+	leaderboards.Queue = settings.QueueRanked
 
 	// As this database interaction has multiple steps, begin a transaction to protect against race conditions.
 	transaction, err := db.Begin()
@@ -592,6 +740,8 @@ func GetLeaderboards(publicID string, start uint64, count uint64) (leaderboards
 			&winRatio,
 			&leaderboards.User.RankedTotal,
 			&leaderboards.User.PublicID,
+			&leaderboards.User.Tier,
+			&leaderboards.User.Division,
 			&leaderboards.User.Rank,
 		)
 
@@ -611,8 +761,11 @@ func GetLeaderboards(publicID string, start uint64, count uint64) (leaderboards
 				leaderboards.User.WinRatio = *winRatio
 			}
 
-			// Also write the leaderboards size to the retrun variable for the specific user.
+			// Also write the leaderboards size, and whether their rating is provisional, to the retrun variable for the
+			// specific user.
 			leaderboards.User.OutOf = leaderboardsCount
+			leaderboards.User.Provisional = provisional(leaderboards.User.Wins, leaderboards.User.Draws, leaderboards.User.Losses)
+			leaderboards.User.Tier, leaderboards.User.Division = placement(leaderboards.User.Tier, leaderboards.User.Division, leaderboards.User.MMR)
 		}
 	}
 
@@ -661,6 +814,8 @@ func GetLeaderboards(publicID string, start uint64, count uint64) (leaderboards
 			&winRatio,
 			&row.RankedTotal,
 			&row.PublicID,
+			&row.Tier,
+			&row.Division,
 			&row.Rank,
 		)
 
@@ -676,6 +831,9 @@ func GetLeaderboards(publicID string, start uint64, count uint64) (leaderboards
 			row.WinRatio = *winRatio
 		}
 
+		row.Provisional = provisional(row.Wins, row.Draws, row.Losses)
+		row.Tier, row.Division = placement(row.Tier, row.Division, row.MMR)
+
 		// Add the new leaderboards row to the (Leaderboards) array of the return variable.
 		leaderboards.Leaderboards = append(leaderboards.Leaderboards, row)
 	}
@@ -724,6 +882,11 @@ func GetMatchHistory(databaseID uint64) (history types.MatchHistory, err error)
 			&row.WinnerHandle,
 			&row.WinnerPublicID,
 			&row.EndTime,
+			&row.Queue,
+			&row.Player1MMRBefore,
+			&row.Player1MMRAfter,
+			&row.Player2MMRBefore,
+			&row.Player2MMRAfter,
 		)
 
 		// Exit early on error.
@@ -802,7 +965,35 @@ func CheckAuthToken(publicID string, authToken string) (err error) {
 	return nil
 }
 
-// userExists retruns true if the user with the specified handle exists.
+// HandleInUse returns true if a user with the specified handle, or any handle with the same canonical key, exists.
+func HandleInUse(handle string) (inUse bool, err error) {
+	return userExists(handle)
+}
+
+// EmailInUse returns true if a user with the specified email address, or any address with the same canonical form, exists.
+// exact is true if the address in use is the specified address itself, rather than an equivalent one.
+func EmailInUse(email string) (inUse bool, exact bool, err error) {
+
+	// Prepare a statement that will check for the existence of a user with the specified email. Exit early on error.
+	statement, err := db.Prepare(psCheckEmail)
+	if err != nil {
+		return false, false, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the users table for rows with the canonical form of the specified email, and with the email itself. Scan the
+	// results into the return variables, and exit early on error.
+	err = statement.QueryRow(validation.CanonicalEmail(email), email).Scan(&inUse, &exact)
+	if err != nil && err != sql.ErrNoRows {
+		return false, false, err
+	}
+
+	return inUse, exact, nil
+}
+
+// userExists retruns true if a user with the specified handle, or any handle with the same canonical key, exists.
 func userExists(handle string) (exists bool, err error) {
 
 	// Prepare a statement that will check for the existence of the specified user. Exit early on error.
@@ -814,9 +1005,9 @@ func userExists(handle string) (exists bool, err error) {
 	// Defer closing of the statement so that it is cleaned up properly when this function exits.
 	defer statement.Close()
 
-	// Query the users table for the row with the specified handle. Scan the result into the return variable,
-	// and exit early on error.
-	err = statement.QueryRow(handle).Scan(&exists)
+	// Query the users table for the row with the canonical key for the specified handle. Scan the result into the
+	// return variable, and exit early on error.
+	err = statement.QueryRow(validation.CanonicalHandle(handle)).Scan(&exists)
 	if err != nil && err != sql.ErrNoRows {
 		return false, err
 	}
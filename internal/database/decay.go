@@ -0,0 +1,114 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/6a/blade-ii-api/internal/types"
+)
+
+// Get the "id", match stats columns, and the number of seconds since the "last_ranked" column, for every row in the profiles table that last played a ranked match more than the specified number of seconds ago, and whose rating has not already decayed today. ID's 99 or less are excluded due to being reserved for admin accounts.
+var psGetDecayCandidates = fmt.Sprintf("SELECT `id`, `mmr`, `rating_deviation`, `rating_volatility`, `wins`, `draws`, `losses`, COALESCE(`tier`, ''), COALESCE(`division`, 0), TIMESTAMPDIFF(SECOND, `last_ranked`, NOW()) FROM `%v`.`%v` WHERE `id` >= 100 AND `last_ranked` < DATE_SUB(NOW(), INTERVAL ? SECOND) AND (`last_decay` IS NULL OR DATE(`last_decay`) < CURDATE());", dbname, dbtableProfiles)
+
+// Update the "mmr", "rating_deviation", "tier", and "division" columns, and set the "last_decay" column to the current time, for the row in the profiles table with the specified database ID.
+var psDecayRating = fmt.Sprintf("UPDATE `%v`.`%v` SET `mmr` = ?, `rating_deviation` = ?, `tier` = ?, `division` = ?, `last_decay` = NOW() WHERE `id` = ?;", dbname, dbtableProfiles)
+
+// GetDecayCandidates returns every player who has not played a ranked match for at least the specified duration, and
+// whose rating has not already decayed today.
+func GetDecayCandidates(inactive time.Duration) (candidates []types.DecayCandidate, err error) {
+
+	// Prepare a statement that will get the candidates. Exit early on error.
+	statement, err := db.Prepare(psGetDecayCandidates)
+	if err != nil {
+		return candidates, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the profiles table for the candidates. Exit early on error.
+	rows, err := statement.Query(int64(inactive.Seconds()))
+	if err != nil {
+		return candidates, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all the rows, scanning each into a new candidate. Exit early on error.
+	for rows.Next() {
+		candidate := types.DecayCandidate{}
+		var inactiveSeconds int64
+		err = rows.Scan(
+			&candidate.DatabaseID,
+			&candidate.MatchStats.MMR,
+			&candidate.MatchStats.Deviation,
+			&candidate.MatchStats.Volatility,
+			&candidate.MatchStats.Wins,
+			&candidate.MatchStats.Draws,
+			&candidate.MatchStats.Losses,
+			&candidate.MatchStats.Tier,
+			&candidate.MatchStats.Division,
+			&inactiveSeconds,
+		)
+
+		if err != nil {
+			return candidates, err
+		}
+
+		candidate.Inactive = time.Duration(inactiveSeconds) * time.Second
+		candidates = append(candidates, candidate)
+	}
+
+	return candidates, rows.Err()
+}
+
+// DecayRating sets the mmr, rating deviation, tier, and division for the player with the specified database ID to the
+// decayed values in the specified match stats, records the change in the rating history, and marks the player's rating
+// as having decayed today.
+func DecayRating(databaseID uint64, decayed types.MatchStats) (err error) {
+
+	// As this database interaction has multiple steps, begin a transaction so that the change is only recorded in the
+	// rating history if it is applied.
+	transaction, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Record the change in rating, before the profile is updated. Exit early on error.
+	err = addRatingHistory(transaction, databaseID, nil, types.RatingChangeDecay, decayed)
+	if err != nil {
+		return err
+	}
+
+	// Prepare a statement that will update the row in the profiles table. Exit early on error.
+	statement, err := transaction.Prepare(psDecayRating)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, updating the row in the profiles table. Exit early on error.
+	_, err = statement.Exec(decayed.MMR, decayed.Deviation, decayed.Tier, decayed.Division, databaseID)
+	if err != nil {
+		return err
+	}
+
+	// Commit the transaction, finalizing the change. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
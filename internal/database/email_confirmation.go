@@ -0,0 +1,65 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"fmt"
+)
+
+// Using a join, set the "email_confirmed" column to true for the user with the specified (unexpired) email confirmation token, and expire the token so that it can't be used again.
+var psConfirmEmail = fmt.Sprintf("UPDATE `%[1]v`.`%[2]v` `u` JOIN `%[1]v`.`%[3]v` `t` on `t`.`id` = `u`.`id` SET `u`.`email_confirmed` = 1, `t`.`email_confirmation_expiry` = NOW() WHERE `t`.`email_confirmation` = ? AND `t`.`email_confirmation_expiry` > NOW();", dbname, dbtableUsers, dbtableTokens)
+
+// Get the "email_confirmed" column from the row in the users table with the specified database ID.
+var psGetEmailConfirmed = fmt.Sprintf("SELECT `email_confirmed` FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableUsers)
+
+// ConfirmEmail confirms the email address for the account with the specified email confirmation token, and returns
+// true if the token was valid. Tokens can only be used once, and are invalid after they expire.
+func ConfirmEmail(token string) (confirmed bool, err error) {
+
+	// Prepare a statement that will confirm the email address. Exit early on error.
+	statement, err := db.Prepare(psConfirmEmail)
+	if err != nil {
+		return false, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, confirming the email address for the account with the token. Exit early on error.
+	result, err := statement.Exec(token)
+	if err != nil {
+		return false, err
+	}
+
+	// If no rows were affected, the token was not found, or has expired.
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// EmailConfirmed returns true if the account with the specified database ID has confirmed its email address.
+func EmailConfirmed(databaseID uint64) (confirmed bool, err error) {
+
+	// Prepare a statement that will get the confirmed state for the account. Exit early on error.
+	statement, err := db.Prepare(psGetEmailConfirmed)
+	if err != nil {
+		return false, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the users table for the account, and scan the result into the return variable. Exit early on error.
+	err = statement.QueryRow(databaseID).Scan(&confirmed)
+	if err != nil {
+		return false, err
+	}
+
+	return confirmed, nil
+}
@@ -0,0 +1,38 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Return a row with a value of either true of false, based on whether a row exists in the blocked email domains table with the specified domain, or a domain that it is a subdomain of.
+var psCheckBlockedDomain = fmt.Sprintf("SELECT EXISTS(SELECT * FROM `%v`.`%v` WHERE `domain` = ? OR RIGHT(?, CHAR_LENGTH(`domain`) + 1) = CONCAT('.', `domain`));", dbname, dbtableBlockedDomains)
+
+// EmailDomainBlocked returns true if the specified domain, or any domain it is a subdomain of, is in the blocked email
+// domains table. This supplements the disposable domain list embedded in the validation package, so that domains can be
+// blocked without a redeploy.
+func EmailDomainBlocked(domain string) (blocked bool, err error) {
+
+	// Prepare a statement that will check whether the domain is blocked. Exit early on error.
+	statement, err := db.Prepare(psCheckBlockedDomain)
+	if err != nil {
+		return false, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the blocked email domains table for the domain. Scan the result into the return variable, and exit early
+	// on error.
+	err = statement.QueryRow(domain, domain).Scan(&blocked)
+	if err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	return blocked, nil
+}
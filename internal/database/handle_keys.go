@@ -0,0 +1,173 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/internal/validation"
+)
+
+// Get the "id" and "handle" columns for every row in the users table, ordered by ID.
+var psGetAllHandles = fmt.Sprintf("SELECT `id`, `handle` FROM `%v`.`%v` ORDER BY `id`;", dbname, dbtableUsers)
+
+// Update the "handle_key" column for the row in the users table with the specified database ID.
+var psUpdateHandleKey = fmt.Sprintf("UPDATE `%v`.`%v` SET `handle_key` = ? WHERE `id` = ?;", dbname, dbtableUsers)
+
+// GetHandleKeyCollisions returns every group of existing accounts whose handles share the same canonical key. These
+// must be resolved (by renaming all but one of the accounts in each group) before the handle keys can be backfilled.
+func GetHandleKeyCollisions() (collisions []types.HandleKeyCollision, err error) {
+
+	// Prepare a statement that will get the ID and handle for every user. Exit early on error.
+	statement, err := db.Prepare(psGetAllHandles)
+	if err != nil {
+		return collisions, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the users table for all users.
+	rows, err := statement.Query()
+	if err != nil {
+		return collisions, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Group every user by the canonical key for their handle. The order in which keys are first seen is
+	// stored as well, so that the returned collisions are in a stable order (by lowest database ID).
+	groups := make(map[string]*types.HandleKeyCollision)
+	order := make([]string, 0)
+	for rows.Next() {
+
+		// Scan the current row into temporary variables. Exit early on error.
+		var databaseID uint64
+		var handle string
+		err = rows.Scan(&databaseID, &handle)
+		if err != nil {
+			return collisions, err
+		}
+
+		// Add the user to the group for their canonical key, creating the group if it does not exist yet.
+		key := validation.CanonicalHandle(handle)
+		group, ok := groups[key]
+		if !ok {
+			group = &types.HandleKeyCollision{Key: key}
+			groups[key] = group
+			order = append(order, key)
+		}
+
+		group.DatabaseIDs = append(group.DatabaseIDs, databaseID)
+		group.Handles = append(group.Handles, handle)
+	}
+
+	// Check for any errors that occurred during iteration.
+	err = rows.Err()
+	if err != nil {
+		return collisions, err
+	}
+
+	// Any group with more than one user is a collision.
+	collisions = make([]types.HandleKeyCollision, 0)
+	for _, key := range order {
+		if len(groups[key].DatabaseIDs) > 1 {
+			collisions = append(collisions, *groups[key])
+		}
+	}
+
+	return collisions, nil
+}
+
+// BackfillHandleKeys sets the "handle_key" column for every user, based on their current handle. Returns an error,
+// without making any changes, if there are any unresolved collisions (see GetHandleKeyCollisions).
+func BackfillHandleKeys() (updated int, err error) {
+
+	// Refuse to backfill if any of the existing handles collide, as the unique index on the handle key would
+	// reject the update part way through.
+	collisions, err := GetHandleKeyCollisions()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(collisions) > 0 {
+		return 0, fmt.Errorf("%v handle key collisions must be resolved before backfilling", len(collisions))
+	}
+
+	// As this database interaction has multiple steps, begin a transaction to protect against race conditions.
+	transaction, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will get the ID and handle for every user. Exit early on error.
+	statement, err := transaction.Prepare(psGetAllHandles)
+	if err != nil {
+		return 0, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the users table for all users, and read them into memory - the rows must be closed before the
+	// updates below can be run on the same transaction.
+	rows, err := statement.Query()
+	if err != nil {
+		return 0, err
+	}
+
+	keys := make(map[uint64]string)
+	for rows.Next() {
+		var databaseID uint64
+		var handle string
+		err = rows.Scan(&databaseID, &handle)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		keys[databaseID] = validation.CanonicalHandle(handle)
+	}
+
+	// Check for any errors that occurred during iteration, then close the rows.
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	// Prepare a statement that will update the handle key for a single user. Exit early on error.
+	statement, err = transaction.Prepare(psUpdateHandleKey)
+	if err != nil {
+		return 0, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Write the canonical key for each user. Exit early on error.
+	for databaseID, key := range keys {
+		_, err = statement.Exec(key, databaseID)
+		if err != nil {
+			return updated, err
+		}
+
+		updated++
+	}
+
+	// Commit the transaction, essentially finalizing all the changes that were just made. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
+}
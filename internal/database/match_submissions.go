@@ -0,0 +1,76 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/6a/blade-ii-api/internal/types"
+)
+
+// Get the "payload_hash" and "response" columns from the row in the match submissions table with the specified key.
+var psGetMatchSubmission = fmt.Sprintf("SELECT `payload_hash`, `response` FROM `%v`.`%v` WHERE `key` = ?;", dbname, dbtableSubmissions)
+
+// Insert a new row into the match submissions table, setting "key", "payload_hash", and "response" with the specified values.
+var psAddMatchSubmission = fmt.Sprintf("INSERT INTO `%v`.`%v` (`key`, `payload_hash`, `response`) VALUES (?, ?, ?);", dbname, dbtableSubmissions)
+
+// GetMatchSubmission returns the processed match result submission with the specified key, or nil if no submission
+// with the key has been processed.
+func GetMatchSubmission(key string) (submission *types.MatchSubmission, err error) {
+
+	// Prepare a statement that will get the submission. Exit early on error.
+	statement, err := db.Prepare(psGetMatchSubmission)
+	if err != nil {
+		return submission, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the match submissions table. Not found = the submission has not been processed, so nil is returned.
+	found := types.MatchSubmission{Keys: []string{key}}
+	err = statement.QueryRow(key).Scan(&found.PayloadHash, &found.Response)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return submission, err
+	}
+
+	return &found, nil
+}
+
+// addMatchSubmission records the specified match result submission as processed under each of its keys, as part of the
+// specified transaction, so that it is only recorded if the match is. If a submission with any of the same keys has
+// already been recorded, such as by a concurrent retry, an error is returned so that the match is not rated again.
+func addMatchSubmission(transaction *sql.Tx, submission types.MatchSubmission) (err error) {
+
+	// Prepare a statement that will add the submission. Exit early on error.
+	statement, err := transaction.Prepare(psAddMatchSubmission)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, adding the submission under each key. A duplicate key means that the submission was already
+	// processed.
+	for _, key := range submission.Keys {
+		_, err = statement.Exec(key, submission.PayloadHash, submission.Response)
+		if err != nil {
+			if strings.Contains(err.Error(), "Error 1062") {
+				return errors.New("Match already submitted")
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Get the "privilege" column from the row in the users table with the specified database ID.
+var psGetPrivilegeByID = fmt.Sprintf("SELECT `privilege` FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableUsers)
+
+// Get the "privilege" column from the row in the users table with the specified database ID, locking the row until the end of the current transaction.
+var psGetPrivilegeByIDForUpdate = fmt.Sprintf("SELECT `privilege` FROM `%v`.`%v` WHERE `id` = ? FOR UPDATE;", dbname, dbtableUsers)
+
+// Get the number of rows in the users table with the specified privilege level, locking the matching rows until the end of the current transaction.
+var psCountPrivilegedForUpdate = fmt.Sprintf("SELECT COUNT(*) FROM `%v`.`%v` WHERE `privilege` = ? FOR UPDATE;", dbname, dbtableUsers)
+
+// Update the "privilege" column for the row in the users table with the specified database ID.
+var psUpdatePrivilege = fmt.Sprintf("UPDATE `%v`.`%v` SET `privilege` = ? WHERE `id` = ?;", dbname, dbtableUsers)
+
+// Insert a new row into the privilege audit table, setting "actor", "target", "old_privilege", and "new_privilege" with the specified values.
+var psInsertPrivilegeAudit = fmt.Sprintf("INSERT INTO `%v`.`%v` (`actor`, `target`, `old_privilege`, `new_privilege`) VALUES (?, ?, ?, ?);", dbname, dbtablePrivilegeAudit)
+
+// ValidPrivilege returns true if the specified value is one of the known privilege levels.
+func ValidPrivilege(privilege uint8) bool {
+	return privilege <= ServerAdminPrivilege
+}
+
+// GetPrivilege returns the privilege level for the user with the specified database ID.
+func GetPrivilege(databaseID uint64) (privilege uint8, err error) {
+
+	// Prepare a statement that will get the privilege level for the specified user. Exit early on error.
+	statement, err := db.Prepare(psGetPrivilegeByID)
+	if err != nil {
+		return privilege, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the row in the users table for the specified user, and scan the privilege column into
+	// the return variable. Exit early on error.
+	err = statement.QueryRow(databaseID).Scan(&privilege)
+	if err != nil {
+		return privilege, err
+	}
+
+	return privilege, nil
+}
+
+// UpdatePrivilege sets the privilege level of the target user, and writes a record of the change to the
+// privilege audit table. Returns the privilege level that the target had before the update.
+//
+// Returns an error if the update would remove the last remaining server admin.
+func UpdatePrivilege(actorDatabaseID uint64, targetDatabaseID uint64, privilege uint8) (oldPrivilege uint8, err error) {
+
+	// As this database interaction has multiple steps, begin a transaction to protect against race conditions.
+	transaction, err := db.Begin()
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will get (and lock) the current privilege level of the target user. Exit early on error.
+	statement, err := transaction.Prepare(psGetPrivilegeByIDForUpdate)
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the users table for the target user's current privilege level. Exit early on error.
+	err = statement.QueryRow(targetDatabaseID).Scan(&oldPrivilege)
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// If the target is currently a server admin and is being demoted, ensure that they are not the last
+	// remaining server admin - otherwise nobody would be able to manage privileges anymore.
+	if oldPrivilege == ServerAdminPrivilege && privilege < ServerAdminPrivilege {
+
+		// Prepare a statement that will count (and lock) all the server admins. Exit early on error.
+		statement, err = transaction.Prepare(psCountPrivilegedForUpdate)
+		if err != nil {
+			return oldPrivilege, err
+		}
+
+		// Defer closing of the statement so that it is cleaned up properly when this function exits.
+		defer statement.Close()
+
+		// Query the users table for the number of server admins. Exit early on error.
+		var serverAdminCount uint64
+		err = statement.QueryRow(ServerAdminPrivilege).Scan(&serverAdminCount)
+		if err != nil {
+			return oldPrivilege, err
+		}
+
+		// Return an error if the target is the only server admin.
+		if serverAdminCount <= 1 {
+			return oldPrivilege, errors.New("Cannot demote the last remaining server admin")
+		}
+	}
+
+	// Prepare a statement that will update the privilege level for the target user. Exit early on error.
+	statement, err = transaction.Prepare(psUpdatePrivilege)
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, updating the privilege column in the row for the target user. Exit early on error.
+	_, err = statement.Exec(privilege, targetDatabaseID)
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// Prepare a statement that will add a row to the privilege audit table. Exit early on error.
+	statement, err = transaction.Prepare(psInsertPrivilegeAudit)
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Record who made the change, who it was made to, and the before/after values. Exit early on error.
+	_, err = statement.Exec(actorDatabaseID, targetDatabaseID, oldPrivilege, privilege)
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	// Commit the transaction, essentially finalizing all the changes that were just made. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return oldPrivilege, err
+	}
+
+	return oldPrivilege, nil
+}
@@ -0,0 +1,56 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/6a/blade-ii-api/pkg/profanity"
+)
+
+// Get the "locale", "term", "mode", and "severity" columns for every row in the profanity terms table.
+var psGetProfanityTerms = fmt.Sprintf("SELECT `locale`, `term`, `mode`, `severity` FROM `%v`.`%v`;", dbname, dbtableProfanity)
+
+// GetProfanityList returns the word list stored in the profanity terms table, for use as a profanity source.
+func GetProfanityList() (list profanity.List, err error) {
+
+	// Prepare a statement that will get all of the profanity terms. Exit early on error.
+	statement, err := db.Prepare(psGetProfanityTerms)
+	if err != nil {
+		return list, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the profanity terms table.
+	rows, err := statement.Query()
+	if err != nil {
+		return list, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Scan each row, and add it to the list. Note that severity is nullable (it is not used by allowlist rows), so it is
+	// scanned into a sql.NullString.
+	for rows.Next() {
+		var locale, term, mode string
+		var severity sql.NullString
+		err = rows.Scan(&locale, &term, &mode, &severity)
+		if err != nil {
+			return list, err
+		}
+
+		err = list.Add(locale, term, mode, severity.String)
+		if err != nil {
+			return list, fmt.Errorf("Profanity term %q: %v", term, err)
+		}
+	}
+
+	return list, rows.Err()
+}
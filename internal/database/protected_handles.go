@@ -0,0 +1,51 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"fmt"
+)
+
+// Get the "handle" column for every system account (ID's 99 or less) and every account with a privilege level above that of a
+// regular user, combined with the "handle" column for the top ranked players on the leaderboards, up to the specified limit.
+var psGetProtectedHandles = fmt.Sprintf("(SELECT `handle` FROM `%[1]v`.`%[3]v` WHERE `id` < 100 OR `privilege` > ?) UNION (SELECT `u`.`handle` FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100%[4]v ORDER BY `p`.`mmr` DESC, `p`.`winratio` DESC LIMIT ?);", dbname, dbtableProfiles, dbtableUsers, leaderboardsCondition())
+
+// GetProtectedHandles returns the handles that new handles must not be confusable with - the handles for system accounts,
+// staff (game and server admins), and the top (leaderboardSize) players on the leaderboards.
+func GetProtectedHandles(leaderboardSize uint64) (handles []string, err error) {
+
+	// Prepare a statement that will get all of the protected handles. Exit early on error.
+	statement, err := db.Prepare(psGetProtectedHandles)
+	if err != nil {
+		return handles, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database for the protected handles.
+	rows, err := statement.Query(UserPrivilege, leaderboardSize)
+	if err != nil {
+		return handles, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Make an empty slice of handles for the return variable, and then scan each row into it.
+	handles = make([]string, 0)
+	for rows.Next() {
+		var handle string
+		err = rows.Scan(&handle)
+		if err != nil {
+			return handles, err
+		}
+
+		handles = append(handles, handle)
+	}
+
+	return handles, rows.Err()
+}
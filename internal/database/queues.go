@@ -0,0 +1,250 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+)
+
+// Get the "mmr", "rating_deviation", "rating_volatility", "wins", "draws", and "losses" columns from the queue ratings table for the specified queue and player, or the specified default rating and no w/d/l if the player has not played in the queue. No rows are returned if the player has no profile.
+var psGetQueueMatchStats = fmt.Sprintf("SELECT COALESCE(`q`.`mmr`, ?), COALESCE(`q`.`rating_deviation`, ?), COALESCE(`q`.`rating_volatility`, ?), COALESCE(`q`.`wins`, 0), COALESCE(`q`.`draws`, 0), COALESCE(`q`.`losses`, 0) FROM `%[1]v`.`%[2]v` `p` LEFT JOIN `%[1]v`.`%[3]v` `q` on `q`.`player` = `p`.`id` AND `q`.`queue` = ? WHERE `p`.`id` = ?;", dbname, dbtableProfiles, dbtableQueueRatings)
+
+// Insert a new row into the queue ratings table for the specified queue and player, or if the row already exists, set the "mmr", "rating_deviation", and "rating_volatility" columns, and add the specified values to the "wins", "draws", and "losses" columns.
+var psSetQueueRating = fmt.Sprintf("INSERT INTO `%v`.`%v` (`queue`, `player`, `mmr`, `rating_deviation`, `rating_volatility`, `wins`, `draws`, `losses`) VALUES (?, ?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `mmr` = VALUES(`mmr`), `rating_deviation` = VALUES(`rating_deviation`), `rating_volatility` = VALUES(`rating_volatility`), `wins` = `wins` + VALUES(`wins`), `draws` = `draws` + VALUES(`draws`), `losses` = `losses` + VALUES(`losses`);", dbname, dbtableQueueRatings)
+
+// Update the "queue" column, "playerN_mmr_before" columns (from each player's current mmr in the queue ratings table for the specified queue, or the specified default rating if the player has not played in the queue) and "playerN_mmr_after" columns (with the specified values) for the row in the matches table with the specified match ID and players.
+var psUpdateQueueMatchRatings = fmt.Sprintf("UPDATE `%[1]v`.`%[2]v` `m` SET `m`.`queue` = ?, `m`.`player1_mmr_before` = COALESCE((SELECT `mmr` FROM `%[1]v`.`%[3]v` WHERE `queue` = ? AND `player` = `m`.`player1`), ?), `m`.`player1_mmr_after` = ?, `m`.`player2_mmr_before` = COALESCE((SELECT `mmr` FROM `%[1]v`.`%[3]v` WHERE `queue` = ? AND `player` = `m`.`player2`), ?), `m`.`player2_mmr_after` = ? WHERE `m`.`id` = ? AND `m`.`player1` = ? AND `m`.`player2` = ?;", dbname, dbtableMatches, dbtableQueueRatings)
+
+// Get the "avatar" and "created" columns from the profiles table, and the "mmr", "rating_deviation", "wins", "draws", "losses", and "winratio" columns from the queue ratings table for the specified queue, for the specified player - with the same defaults as above if the player has not played in the queue.
+var psGetQueueProfile = fmt.Sprintf("SELECT `p`.`avatar`, COALESCE(`q`.`mmr`, ?), COALESCE(`q`.`rating_deviation`, ?), COALESCE(`q`.`wins`, 0), COALESCE(`q`.`draws`, 0), COALESCE(`q`.`losses`, 0), `q`.`winratio`, `p`.`created` FROM `%[1]v`.`%[2]v` `p` LEFT JOIN `%[1]v`.`%[3]v` `q` on `q`.`player` = `p`.`id` AND `q`.`queue` = ? WHERE `p`.`id` = ?;", dbname, dbtableProfiles, dbtableQueueRatings)
+
+// Get the "handle" and "public_id" (as "pid") from the users table, "avatar" from the profiles table, and the "mmr", "wins", "draws", "losses", "winratio", and a generated total from the queue ratings table, for every player in the specified queue. ID's 99 or less are excluded due to being reserved for admin accounts, as are accounts excluded by the leaderboards condition - note that the queue ratings table is aliased as "p", so that the condition applies to the w/d/l in the queue.
+var queueLeaderboardsRows = fmt.Sprintf("SELECT `u`.`handle`, `a`.`avatar`, `p`.`mmr`, `p`.`wins`, `p`.`draws`, `p`.`losses`, `p`.`winratio`, `p`.`wins` + `p`.`draws` + `p`.`losses` AS `total`, `u`.`public_id` AS `pid` FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`player` JOIN `%[1]v`.`%[4]v` `a` on `a`.`id` = `p`.`player` WHERE `p`.`queue` = ? AND `p`.`player` >= 100%[5]v", dbname, dbtableQueueRatings, dbtableUsers, dbtableProfiles, leaderboardsCondition())
+
+// Get the rows above, ranked by mmr and then win ratio, for a range of the ladder for the specified queue, ordered by rank.
+var psGetQueueLeaderboards = fmt.Sprintf("SELECT `t`.`handle`, `t`.`avatar`, `t`.`mmr`, `t`.`wins`, `t`.`draws`, `t`.`losses`, `t`.`winratio`, `t`.`total`, `t`.`pid`, RANK() OVER (ORDER BY `t`.`mmr` DESC, `t`.`winratio` DESC) AS `rank` FROM (%v) AS t ORDER BY `rank` LIMIT ? OFFSET ?;", queueLeaderboardsRows)
+
+// Get the same columns as above, for the player with the specified public ID in the ladder for the specified queue.
+var psGetQueueIndividualRank = fmt.Sprintf("SELECT * FROM (SELECT `t`.`handle`, `t`.`avatar`, `t`.`mmr`, `t`.`wins`, `t`.`draws`, `t`.`losses`, `t`.`winratio`, `t`.`total`, `t`.`pid`, RANK() OVER (ORDER BY `t`.`mmr` DESC, `t`.`winratio` DESC) AS `rank` FROM (%v) AS t) AS rt WHERE `pid` = ?;", queueLeaderboardsRows)
+
+// Get the number of players in the ladder for the specified queue.
+var psGetQueueLeaderboardsCount = fmt.Sprintf("SELECT COUNT(*) FROM `%[1]v`.`%[2]v` `p` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `p`.`player` WHERE `p`.`queue` = ? AND `p`.`player` >= 100%[4]v;", dbname, dbtableQueueRatings, dbtableUsers, leaderboardsCondition())
+
+// queueProvisional returns true if a player with the specified match stats in the specified unranked queue still has a
+// provisional rating, according to the queue's own k policy rather than the ranked placement games.
+func queueProvisional(queue string, wins uint32, draws uint32, losses uint32) bool {
+	policy := settings.Queues[queue]
+	return policy.Provisional(wins + draws + losses)
+}
+
+// GetQueueMatchStats returns the match stats (mmr, rating deviation and volatility, w/d/l) for the specified client in
+// the ladder for the specified unranked queue. Clients that have not played in the queue start with the default
+// rating, as for a new account.
+func GetQueueMatchStats(queue string, databaseID uint64) (matchStats types.MatchStats, err error) {
+
+	// Prepare a statement that will get the match stats for the specified user. Exit early on error.
+	statement, err := db.Prepare(psGetQueueMatchStats)
+	if err != nil {
+		return matchStats, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the row in the queue ratings table for the specified user, and read the returned columns into the return
+	// variables of this function. Exit early on error.
+	err = statement.QueryRow(elo.Default, elo.DefaultDeviation, elo.DefaultVolatility, queue, databaseID).Scan(&matchStats.MMR, &matchStats.Deviation, &matchStats.Volatility, &matchStats.Wins, &matchStats.Draws, &matchStats.Losses)
+	if err != nil {
+		return matchStats, err
+	}
+
+	return matchStats, nil
+}
+
+// UpdateQueueMatchStats updates the rating for the two specified clients, as well as w/d/l stats, in the ladder for the
+// specified unranked queue. Each change is recorded in the rating history for the queue. If a match ID is specified,
+// each player's rating before and after the match is also recorded in the matches table, along with the queue, and the
+// update fails if there is no such match between the two clients. If a submission is specified, it is recorded as
+// processed, and the update fails if it already has been.
+func UpdateQueueMatchStats(queue string, matchID *uint64, client1DatabaseID uint64, client1MatchStats types.MatchStats, client2DatabaseID uint64, client2MatchStats types.MatchStats, winner elo.Player, submission *types.MatchSubmission) (err error) {
+
+	// As this database interaction has multiple steps, begin a transaction to protect against race conditions.
+	transaction, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// If a submission was specified, record it first, so that nothing else is changed if it was already processed.
+	// Exit early on error.
+	if submission != nil {
+		err = addMatchSubmission(transaction, *submission)
+		if err != nil {
+			return err
+		}
+	}
+
+	// If a match ID was specified, record each player's rating before and after the match in the matches table. This
+	// is done before the ladder is updated, as the ratings before the match are read from the ladder.
+	if matchID != nil {
+		err = updateMatchRatings(transaction, psUpdateQueueMatchRatings, queue, queue, elo.Default, client1MatchStats.MMR, queue, elo.Default, client2MatchStats.MMR, *matchID, client1DatabaseID, client2DatabaseID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Prepare a statement that will set the rating for a player in the queue. Exit early on error.
+	statement, err := transaction.Prepare(psSetQueueRating)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Record the change in rating for each player, and then set their rating, adding the outcome of the match to their
+	// w/d/l. Exit early on error.
+	players := []struct {
+		databaseID uint64
+		matchStats types.MatchStats
+		player     elo.Player
+	}{
+		{client1DatabaseID, client1MatchStats, elo.Player1},
+		{client2DatabaseID, client2MatchStats, elo.Player2},
+	}
+
+	for _, p := range players {
+		err = addQueueRatingHistory(transaction, queue, p.databaseID, matchID, p.matchStats)
+		if err != nil {
+			return err
+		}
+
+		win, draw, loss := matchOutcome(winner, p.player)
+		_, err = statement.Exec(queue, p.databaseID, p.matchStats.MMR, p.matchStats.Deviation, p.matchStats.Volatility, win, draw, loss)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction, essentially finalizing all the changes that were just made.
+	return transaction.Commit()
+}
+
+// GetQueueProfile returns profile data for the user with the specified databaseID, with the rating and w/d/l from the
+// ladder for the specified unranked queue. Tiers and seasons only apply to the ranked queue, so they are left empty.
+func GetQueueProfile(queue string, databaseID uint64) (profile types.ProfileResponsePayload, err error) {
+
+	// Prepare a statement that will get the profile data for the specified user. Exit early on error.
+	statement, err := db.Prepare(psGetQueueProfile)
+	if err != nil {
+		return profile, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the profile and queue rating for the specified user. Note the edge case for winratio - it is null if the
+	// user has not played in the queue, so it is scanned into temporary float32 pointer.
+	var winRatio *float32 = nil
+	err = statement.QueryRow(elo.Default, elo.DefaultDeviation, queue, databaseID).Scan(&profile.Avatar, &profile.MMR, &profile.Deviation, &profile.Wins, &profile.Draws, &profile.Losses, &winRatio, &profile.Created)
+	if err != nil {
+		return profile, err
+	}
+
+	if winRatio != nil {
+		profile.WinRatio = *winRatio
+	}
+
+	profile.Queue = queue
+	profile.RankedTotal = int64(profile.Wins + profile.Draws + profile.Losses)
+	profile.Provisional = queueProvisional(queue, profile.Wins, profile.Draws, profile.Losses)
+
+	return profile, nil
+}
+
+// GetQueueLeaderboards returns the ladder for the specified unranked queue, starting at rank (start) returning (count)
+// results, as well as an extra blob containing the details for the user specified.
+func GetQueueLeaderboards(queue string, publicID string, start uint64, count uint64) (leaderboards types.LeaderboardResponsePayload, err error) {
+	leaderboards.Queue = queue
+
+	// Prepare a statement that will get the number of players in the ladder. Exit early on error.
+	statement, err := db.Prepare(psGetQueueLeaderboardsCount)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the queue ratings table for the count. Exit early on error.
+	var leaderboardsCount uint64
+	err = statement.QueryRow(queue).Scan(&leaderboardsCount)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// If a public ID was provided, get the row specific to that user as well.
+	if publicID != "" {
+
+		// Prepare a statement that will get the ladder row for a specific user. Exit early on error.
+		statement, err = db.Prepare(psGetQueueIndividualRank)
+		if err != nil {
+			return leaderboards, err
+		}
+
+		// Defer closing of the statement so that it is cleaned up properly when this function exits.
+		defer statement.Close()
+
+		// Query the row for the user. Only report db errors, not found = silently return default values.
+		leaderboards.User, err = scanLeaderboardRow(statement.QueryRow(queue, publicID))
+		if err == nil {
+			leaderboards.User.OutOf = leaderboardsCount
+			leaderboards.User.Provisional = queueProvisional(queue, leaderboards.User.Wins, leaderboards.User.Draws, leaderboards.User.Losses)
+		} else if err != sql.ErrNoRows {
+			return leaderboards, err
+		}
+	}
+
+	// Prepare a statement that will get the range of the ladder. Exit early on error.
+	statement, err = db.Prepare(psGetQueueLeaderboards)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the queue ratings table for the range.
+	rows, err := statement.Query(queue, count, start)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all of the rows, adding each to the return variable. Exit early on error.
+	leaderboards.Leaderboards = make([]types.LeaderboardRow, 0)
+	for rows.Next() {
+		row, err := scanLeaderboardRow(rows)
+		if err != nil {
+			return leaderboards, err
+		}
+
+		row.OutOf = leaderboardsCount
+		row.Provisional = queueProvisional(queue, row.Wins, row.Draws, row.Losses)
+		leaderboards.Leaderboards = append(leaderboards.Leaderboards, row)
+	}
+
+	return leaderboards, rows.Err()
+}
@@ -0,0 +1,112 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Insert a new row into the rate limits table for the specified key, with a count of 1. If the row already exists, its count
+// is incremented, unless its window started more than the specified number of seconds ago, in which case a new window is
+// started with a count of 1. Note that MySQL applies the assignments in order, so the window start is checked before it is reset.
+var psIncrementRateLimit = fmt.Sprintf("INSERT INTO `%v`.`%v` (`key`, `window_start`, `count`) VALUES (?, NOW(), 1) "+
+	"ON DUPLICATE KEY UPDATE "+
+	"`count` = IF(`window_start` <= DATE_SUB(NOW(), INTERVAL ? SECOND), 1, `count` + 1), "+
+	"`window_start` = IF(`window_start` <= DATE_SUB(NOW(), INTERVAL ? SECOND), NOW(), `window_start`);", dbname, dbtableRateLimits)
+
+// Get the "count" column from the row in the rate limits table with the specified key, or 0 if its window started more than the specified number of seconds ago.
+var psGetRateLimitCount = fmt.Sprintf("SELECT IF(`window_start` <= DATE_SUB(NOW(), INTERVAL ? SECOND), 0, `count`) FROM `%v`.`%v` WHERE `key` = ?;", dbname, dbtableRateLimits)
+
+// ConsumeRateLimit records a request for the specified key (such as a route and IP address), and returns true if the
+// number of requests for that key in the current window, including this one, is within the limit. Windows are fixed,
+// starting with the first request after the previous window has ended.
+func ConsumeRateLimit(key string, limit uint64, window time.Duration) (allowed bool, err error) {
+	count, err := IncrementRateLimit(key, window)
+	if err != nil {
+		return false, err
+	}
+
+	return count <= limit, nil
+}
+
+// IncrementRateLimit records a request for the specified key, and returns the number of requests for that key in the
+// current window, including this one.
+func IncrementRateLimit(key string, window time.Duration) (count uint64, err error) {
+
+	// As this database interaction has multiple steps, begin a transaction so that the count that is read is the
+	// count that was written.
+	transaction, err := db.Begin()
+	if err != nil {
+		return count, err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will increment the count for the key. Exit early on error.
+	statement, err := transaction.Prepare(psIncrementRateLimit)
+	if err != nil {
+		return count, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, incrementing the count (or starting a new window). Exit early on error.
+	seconds := uint64(window.Seconds())
+	_, err = statement.Exec(key, seconds, seconds)
+	if err != nil {
+		return count, err
+	}
+
+	// Prepare a statement that will get the count for the key. Exit early on error.
+	statement, err = transaction.Prepare(psGetRateLimitCount)
+	if err != nil {
+		return count, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the rate limits table for the count, and scan it into the return variable. Exit early on error.
+	err = statement.QueryRow(seconds, key).Scan(&count)
+	if err != nil {
+		return count, err
+	}
+
+	// Commit the transaction, essentially finalizing the new count. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return count, err
+	}
+
+	return count, nil
+}
+
+// GetRateLimitCount returns the number of requests for the specified key in the current window, without recording a
+// new request. Returns 0 if there have been no requests, or the last window has ended.
+func GetRateLimitCount(key string, window time.Duration) (count uint64, err error) {
+
+	// Prepare a statement that will get the count for the key. Exit early on error.
+	statement, err := db.Prepare(psGetRateLimitCount)
+	if err != nil {
+		return count, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the rate limits table for the count, and scan it into the return variable. No rows means that there have
+	// been no requests for the key.
+	err = statement.QueryRow(uint64(window.Seconds()), key).Scan(&count)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+
+	return count, err
+}
@@ -0,0 +1,180 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+)
+
+// Insert a new row into the rating history table for the player with the specified database ID, setting "match_id", "reason", "mmr_after", and "deviation_after" with the specified values, and "mmr_before" and "deviation_before" from the player's current values in the profiles table.
+var psAddRatingHistory = fmt.Sprintf("INSERT INTO `%[1]v`.`%[2]v` (`player`, `match_id`, `reason`, `mmr_before`, `mmr_after`, `deviation_before`, `deviation_after`) SELECT `id`, ?, ?, `mmr`, ?, `rating_deviation`, ? FROM `%[1]v`.`%[3]v` WHERE `id` = ?;", dbname, dbtableRatingHistory, dbtableProfiles)
+
+// Insert a new row into the rating history table for the player with the specified database ID, setting "queue", "match_id", "mmr_after", and "deviation_after" with the specified values, "reason" to a match, and "mmr_before" and "deviation_before" from the player's current values in the queue ratings table for the queue (or the specified default rating if the player has not played in the queue).
+var psAddQueueRatingHistory = fmt.Sprintf("INSERT INTO `%[1]v`.`%[2]v` (`player`, `queue`, `match_id`, `reason`, `mmr_before`, `mmr_after`, `deviation_before`, `deviation_after`) SELECT `p`.`id`, ?, ?, '%[5]v', COALESCE(`q`.`mmr`, ?), ?, COALESCE(`q`.`rating_deviation`, ?), ? FROM `%[1]v`.`%[3]v` `p` LEFT JOIN `%[1]v`.`%[4]v` `q` on `q`.`player` = `p`.`id` AND `q`.`queue` = ? WHERE `p`.`id` = ?;", dbname, dbtableRatingHistory, dbtableProfiles, dbtableQueueRatings, types.RatingChangeMatch)
+
+// Get the number of rows in the rating history table for the specified player and queue.
+var psGetRatingHistoryCount = fmt.Sprintf("SELECT COUNT(*) FROM `%v`.`%v` WHERE `player` = ? AND `queue` = ?;", dbname, dbtableRatingHistory)
+
+// Get the "match_id", "reason", "mmr_before", "mmr_after", "deviation_after", and "created" columns for a range of the rows in the rating history table for the specified player and queue, in chronological order.
+var psGetRatingHistory = fmt.Sprintf("SELECT `match_id`, `reason`, `mmr_before`, `mmr_after`, `deviation_after`, `created` FROM `%v`.`%v` WHERE `player` = ? AND `queue` = ? ORDER BY `created`, `id` LIMIT ? OFFSET ?;", dbname, dbtableRatingHistory)
+
+// Get the same columns as above for every nth row in the rating history table for the specified player and queue, in chronological order, where n is chosen so that at most the specified number of rows (plus the latest row) are returned. The latest row is always included, so that the series ends at the player's current rating.
+var psGetRatingHistorySampled = fmt.Sprintf("SELECT `match_id`, `reason`, `mmr_before`, `mmr_after`, `deviation_after`, `created` FROM (SELECT *, ROW_NUMBER() OVER (ORDER BY `created`, `id`) AS `n`, COUNT(*) OVER () AS `total` FROM `%v`.`%v` WHERE `player` = ? AND `queue` = ?) AS `h` WHERE MOD(`n` - 1, CEIL(`total` / ?)) = 0 OR `n` = `total` ORDER BY `n`;", dbname, dbtableRatingHistory)
+
+// addRatingHistory records a change in the ranked rating for the player with the specified database ID, as part of the
+// specified transaction. It must be called before the player's profile is updated, as the values before the change are
+// read from the profile.
+func addRatingHistory(transaction *sql.Tx, databaseID uint64, matchID *uint64, reason types.RatingChangeReason, after types.MatchStats) (err error) {
+
+	// Prepare a statement that will add the rating history row. Exit early on error.
+	statement, err := transaction.Prepare(psAddRatingHistory)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, adding the rating history row. Exit early on error.
+	_, err = statement.Exec(matchID, reason, after.MMR, after.Deviation, databaseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// addQueueRatingHistory records a change in rating due to a match for the player with the specified database ID, in the
+// ladder for the specified unranked queue, as part of the specified transaction. It must be called before the player's
+// rating in the queue is updated, as the values before the change are read from the ladder.
+func addQueueRatingHistory(transaction *sql.Tx, queue string, databaseID uint64, matchID *uint64, after types.MatchStats) (err error) {
+
+	// Prepare a statement that will add the rating history row. Exit early on error.
+	statement, err := transaction.Prepare(psAddQueueRatingHistory)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, adding the rating history row. Exit early on error.
+	_, err = statement.Exec(queue, matchID, elo.Default, after.MMR, elo.DefaultDeviation, after.Deviation, queue, databaseID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetRatingHistory returns a range of the rating history for the specified player in the specified queue, starting at
+// (from) and returning up to (count) points, in chronological order. The total number of points in the player's rating
+// history for the queue is also returned.
+func GetRatingHistory(databaseID uint64, queue string, from uint64, count uint64) (history types.RatingHistoryResponsePayload, err error) {
+	history.Queue = queue
+
+	// Prepare a statement that will get the size of the player's rating history. Exit early on error.
+	statement, err := db.Prepare(psGetRatingHistoryCount)
+	if err != nil {
+		return history, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the rating history table, and scan the count into the return variable. Exit early on error.
+	err = statement.QueryRow(databaseID, queue).Scan(&history.Total)
+	if err != nil {
+		return history, err
+	}
+
+	// Prepare a statement that will get the range of the player's rating history. Exit early on error.
+	statement, err = db.Prepare(psGetRatingHistory)
+	if err != nil {
+		return history, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the rating history table, and read the rows into the return variable.
+	rows, err := statement.Query(databaseID, queue, count, from)
+	if err != nil {
+		return history, err
+	}
+
+	history.Points, err = scanRatingHistory(rows)
+
+	return history, err
+}
+
+// GetRatingHistorySampled returns the rating history for the specified player in the specified queue, downsampled to at
+// most (points) points (plus the latest point), in chronological order. The total number of points in the player's
+// rating history for the queue is also returned.
+func GetRatingHistorySampled(databaseID uint64, queue string, points uint64) (history types.RatingHistoryResponsePayload, err error) {
+	history.Queue = queue
+
+	// Prepare a statement that will get the size of the player's rating history. Exit early on error.
+	statement, err := db.Prepare(psGetRatingHistoryCount)
+	if err != nil {
+		return history, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the rating history table, and scan the count into the return variable. Exit early on error.
+	err = statement.QueryRow(databaseID, queue).Scan(&history.Total)
+	if err != nil {
+		return history, err
+	}
+
+	// Prepare a statement that will get the downsampled rating history. Exit early on error.
+	statement, err = db.Prepare(psGetRatingHistorySampled)
+	if err != nil {
+		return history, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the rating history table, and read the rows into the return variable.
+	rows, err := statement.Query(databaseID, queue, points)
+	if err != nil {
+		return history, err
+	}
+
+	history.Points, err = scanRatingHistory(rows)
+
+	return history, err
+}
+
+// scanRatingHistory reads every row from the result of a rating history query, and closes the rows.
+func scanRatingHistory(rows *sql.Rows) (points []types.RatingHistoryPoint, err error) {
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Make an empty slice, so that an empty history is returned as an empty array rather than null.
+	points = make([]types.RatingHistoryPoint, 0)
+
+	// Iterate over all the rows, scanning each into a new point. Exit early on error.
+	for rows.Next() {
+		point := types.RatingHistoryPoint{}
+		err = rows.Scan(&point.MatchID, &point.Reason, &point.MMRBefore, &point.MMRAfter, &point.Deviation, &point.Time)
+		if err != nil {
+			return points, err
+		}
+
+		points = append(points, point)
+	}
+
+	return points, rows.Err()
+}
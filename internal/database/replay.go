@@ -0,0 +1,165 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+)
+
+// Get the "player1" and "player2" columns, and the winner (as an elo.Player), for every finished match in the matches table that was rated in the ranked queue (or whose queue is not known), in chronological order.
+var psGetReplayMatches = fmt.Sprintf("SELECT `player1`, `player2`, CASE `winner` WHEN `player1` THEN %v WHEN `player2` THEN %v ELSE %v END FROM `%v`.`%v` WHERE `phase` = 2 AND (`queue` IS NULL OR `queue` = '%v') ORDER BY `end`, `id`;", elo.Player1, elo.Player2, elo.Draw, dbname, dbtableMatches, settings.QueueRanked)
+
+// Get the "id", and match stats columns, for every row in the profiles table. ID's 99 or less are excluded due to being reserved for admin accounts.
+var psGetAllMatchStats = fmt.Sprintf("SELECT `id`, `mmr`, `rating_deviation`, `rating_volatility`, `wins`, `draws`, `losses`, COALESCE(`tier`, ''), COALESCE(`division`, 0) FROM `%v`.`%v` WHERE `id` >= 100;", dbname, dbtableProfiles)
+
+// Update the "mmr", "rating_deviation", and "rating_volatility" columns, and clear the "tier" and "division" columns so that the player is placed from their new mmr, for the row in the profiles table with the specified database ID.
+var psSetRating = fmt.Sprintf("UPDATE `%v`.`%v` SET `mmr` = ?, `rating_deviation` = ?, `rating_volatility` = ?, `tier` = NULL, `division` = NULL WHERE `id` = ?;", dbname, dbtableProfiles)
+
+// Get the number of rolled over seasons, decays in the rating history, rated matches with reduced gains, finished matches involving an account that has not confirmed its email address, and rows in the unranked queue ladders.
+var psGetReplayAdjustments = fmt.Sprintf("SELECT (SELECT COUNT(*) FROM `%[1]v`.`%[2]v` WHERE `rolled_over` IS NOT NULL), (SELECT COUNT(*) FROM `%[1]v`.`%[3]v` WHERE `reason` = '%[4]v'), (SELECT COUNT(*) FROM `%[1]v`.`%[5]v` WHERE `multiplier` < 1), (SELECT COUNT(DISTINCT `m`.`id`) FROM `%[1]v`.`%[6]v` `m` JOIN `%[1]v`.`%[7]v` `u` ON `u`.`id` IN (`m`.`player1`, `m`.`player2`) WHERE `m`.`phase` = 2 AND `u`.`email_confirmed` = 0), (SELECT COUNT(*) FROM `%[1]v`.`%[8]v`);", dbname, dbtableSeasons, dbtableRatingHistory, types.RatingChangeDecay, dbtablePairings, dbtableMatches, dbtableUsers, dbtableQueueRatings)
+
+// GetReplayMatches returns every finished match that was rated in the ranked queue, or whose queue is not known, in
+// chronological order, for replaying through a rating system.
+func GetReplayMatches() (matches []elo.Match, err error) {
+
+	// Prepare a statement that will get the matches. Exit early on error.
+	statement, err := db.Prepare(psGetReplayMatches)
+	if err != nil {
+		return matches, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the matches table. Exit early on error.
+	rows, err := statement.Query()
+	if err != nil {
+		return matches, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all the rows, scanning each into a new match. Exit early on error.
+	for rows.Next() {
+		match := elo.Match{}
+		err = rows.Scan(&match.Player1, &match.Player2, &match.Winner)
+		if err != nil {
+			return matches, err
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// GetAllMatchStats returns the match stats for every player, by database ID.
+func GetAllMatchStats() (matchStats map[uint64]types.MatchStats, err error) {
+
+	// Prepare a statement that will get the match stats. Exit early on error.
+	statement, err := db.Prepare(psGetAllMatchStats)
+	if err != nil {
+		return matchStats, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the profiles table. Exit early on error.
+	rows, err := statement.Query()
+	if err != nil {
+		return matchStats, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all the rows, scanning each into the return map. Exit early on error.
+	matchStats = make(map[uint64]types.MatchStats)
+	for rows.Next() {
+		var databaseID uint64
+		stats := types.MatchStats{}
+		err = rows.Scan(&databaseID, &stats.MMR, &stats.Deviation, &stats.Volatility, &stats.Wins, &stats.Draws, &stats.Losses, &stats.Tier, &stats.Division)
+		if err != nil {
+			return matchStats, err
+		}
+
+		matchStats[databaseID] = stats
+	}
+
+	return matchStats, rows.Err()
+}
+
+// GetReplayAdjustments returns the number of each kind of adjustment that has been made to the live ratings, which
+// replaying the matches does not reproduce.
+func GetReplayAdjustments() (adjustments types.ReplayAdjustments, err error) {
+
+	// Prepare a statement that will count the adjustments. Exit early on error.
+	statement, err := db.Prepare(psGetReplayAdjustments)
+	if err != nil {
+		return adjustments, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, scanning the counts into the return variable.
+	err = statement.QueryRow().Scan(&adjustments.RolledOverSeasons, &adjustments.Decays, &adjustments.ReducedPairings, &adjustments.UnconfirmedMatches, &adjustments.QueueRatings)
+
+	return adjustments, err
+}
+
+// SetRatings sets the mmr, rating deviation, and volatility for each specified player (by database ID) to the values
+// in the specified match stats, and records each change in the rating history. Each player's tier is cleared, so that
+// they are placed from their new mmr. Either every player is updated, or none are.
+func SetRatings(ratings map[uint64]types.MatchStats) (updated int, err error) {
+
+	// As this database interaction has multiple steps, begin a transaction so that either every row is updated, or none are.
+	transaction, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will update the rating for a single player. Exit early on error.
+	statement, err := transaction.Prepare(psSetRating)
+	if err != nil {
+		return 0, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Record the change in rating for each player, and then update their profile. Exit early on error.
+	for databaseID, matchStats := range ratings {
+		err = addRatingHistory(transaction, databaseID, nil, types.RatingChangeReplay, matchStats)
+		if err != nil {
+			return 0, err
+		}
+
+		_, err = statement.Exec(matchStats.MMR, matchStats.Deviation, matchStats.Volatility, databaseID)
+		if err != nil {
+			return 0, err
+		}
+
+		updated++
+	}
+
+	// Commit the transaction, essentially finalizing all the changes that were just made. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return 0, err
+	}
+
+	return updated, nil
+}
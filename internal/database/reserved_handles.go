@@ -0,0 +1,118 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/6a/blade-ii-api/internal/types"
+)
+
+// Get the "id", "handle", "match", and "reserved_for" columns for every row in the reserved handles table.
+var psGetReservedHandles = fmt.Sprintf("SELECT `id`, `handle`, `match`, `reserved_for` FROM `%v`.`%v`;", dbname, dbtableReserved)
+
+// Insert a new row into the reserved handles table, setting "handle", "match", "reserved_for", and "created_by" with the specified values.
+var psAddReservedHandle = fmt.Sprintf("INSERT INTO `%v`.`%v` (`handle`, `match`, `reserved_for`, `created_by`) VALUES (?, ?, ?, ?);", dbname, dbtableReserved)
+
+// Delete the row in the reserved handles table with the specified ID.
+var psRemoveReservedHandle = fmt.Sprintf("DELETE FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableReserved)
+
+// GetReservedHandles returns every entry in the reserved handles store.
+func GetReservedHandles() (reserved []types.ReservedHandle, err error) {
+
+	// Prepare a statement that will get all of the reserved handles. Exit early on error.
+	statement, err := db.Prepare(psGetReservedHandles)
+	if err != nil {
+		return reserved, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the reserved handles table.
+	rows, err := statement.Query()
+	if err != nil {
+		return reserved, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Make an empty slice of reserved handles for the return variable, and then scan each row into it. Note
+	// that reserved_for is nullable, so it is scanned into a pointer.
+	reserved = make([]types.ReservedHandle, 0)
+	for rows.Next() {
+		row := types.ReservedHandle{}
+		err = rows.Scan(&row.ID, &row.Handle, &row.Match, &row.ReservedFor)
+		if err != nil {
+			return reserved, err
+		}
+
+		reserved = append(reserved, row)
+	}
+
+	return reserved, rows.Err()
+}
+
+// AddReservedHandle adds a new entry to the reserved handles store, and returns its ID. If reservedFor is not nil, it
+// should be the database ID of the only account that may use handles matching the new entry.
+func AddReservedHandle(handle string, match types.ReservedHandleMatch, reservedFor *uint64, createdBy uint64) (id uint64, err error) {
+
+	// Prepare a statement that will add a row to the reserved handles table. Exit early on error.
+	statement, err := db.Prepare(psAddReservedHandle)
+	if err != nil {
+		return id, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, adding the new row. Exit early on error.
+	result, err := statement.Exec(handle, match, reservedFor, createdBy)
+	if err != nil {
+		return id, err
+	}
+
+	// Get the ID of the new row, so that it can be returned to the caller.
+	lastInsertID, err := result.LastInsertId()
+	if err != nil {
+		return id, err
+	}
+
+	return uint64(lastInsertID), nil
+}
+
+// RemoveReservedHandle removes the entry with the specified ID from the reserved handles store.
+func RemoveReservedHandle(id uint64) (err error) {
+
+	// Prepare a statement that will delete a row from the reserved handles table. Exit early on error.
+	statement, err := db.Prepare(psRemoveReservedHandle)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, deleting the row. Exit early on error.
+	result, err := statement.Exec(id)
+	if err != nil {
+		return err
+	}
+
+	// If no rows were affected, there was no entry with the specified ID.
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return errors.New("Reserved handle not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,392 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package database provides an interface through which the application can interact with a database.
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+)
+
+// Get the "id", "name", "start", "end", and "rolled_over" columns for every row in the seasons table, ordered by start time.
+var psGetSeasons = fmt.Sprintf("SELECT `id`, `name`, `start`, `end`, `rolled_over` FROM `%v`.`%v` ORDER BY `start`;", dbname, dbtableSeasons)
+
+// Get the "id", "name", "start", "end", and "rolled_over" columns from the row in the seasons table with the specified ID.
+var psGetSeason = fmt.Sprintf("SELECT `id`, `name`, `start`, `end`, `rolled_over` FROM `%v`.`%v` WHERE `id` = ?;", dbname, dbtableSeasons)
+
+// Return a row with a value of either true of false, based on whether a row exists in the seasons table that overlaps with the specified start and end times.
+var psCheckSeasonOverlap = fmt.Sprintf("SELECT EXISTS(SELECT * FROM `%v`.`%v` WHERE `start` < ? AND `end` > ?);", dbname, dbtableSeasons)
+
+// Insert a new row into the seasons table, setting "name", "start", "end", and "created_by" with the specified values.
+var psAddSeason = fmt.Sprintf("INSERT INTO `%v`.`%v` (`name`, `start`, `end`, `created_by`) VALUES (?, ?, ?, ?);", dbname, dbtableSeasons)
+
+// Get whether the season with the specified ID has ended, and whether it has been rolled over, locking the row until the end of the transaction.
+var psGetSeasonState = fmt.Sprintf("SELECT `end` <= NOW(), `rolled_over` IS NOT NULL FROM `%v`.`%v` WHERE `id` = ? FOR UPDATE;", dbname, dbtableSeasons)
+
+// Insert a row into the season standings table for every player that played in the season, with their rank (among those players), mmr, and season wins, draws, and losses. ID's 99 or less are excluded due to being reserved for admin accounts, as are accounts excluded by the leaderboards condition.
+var psArchiveSeasonStandings = fmt.Sprintf("INSERT INTO `%[1]v`.`%[2]v` (`season`, `player`, `final_rank`, `mmr`, `wins`, `draws`, `losses`) SELECT ?, `p`.`id`, RANK() OVER (ORDER BY `p`.`mmr` DESC, `p`.`winratio` DESC), `p`.`mmr`, `p`.`season_wins`, `p`.`season_draws`, `p`.`season_losses` FROM `%[1]v`.`%[3]v` `p` JOIN `%[1]v`.`%[4]v` `u` on `u`.`id` = `p`.`id` WHERE `p`.`id` >= 100 AND `p`.`season_wins` + `p`.`season_draws` + `p`.`season_losses` > 0%[5]v;", dbname, dbtableStandings, dbtableProfiles, dbtableUsers, leaderboardsCondition())
+
+// The soft reset applied to a player's mmr, which moves it toward the specified target by keeping the specified fraction of the difference.
+const softResetMMR = "ROUND(? + (`mmr` - ?) * ?)"
+
+// Insert a row into the rating history table for every player whose mmr will be changed by the soft reset, with the specified reason.
+var psAddSeasonRatingHistory = fmt.Sprintf("INSERT INTO `%[1]v`.`%[2]v` (`player`, `match_id`, `reason`, `mmr_before`, `mmr_after`, `deviation_before`, `deviation_after`) SELECT `id`, NULL, ?, `mmr`, %[4]v, `rating_deviation`, `rating_deviation` FROM `%[1]v`.`%[3]v` WHERE `id` >= 100 AND `mmr` != %[4]v;", dbname, dbtableRatingHistory, dbtableProfiles, softResetMMR)
+
+// Apply the soft reset to the "mmr" column, reset the "season_wins", "season_draws", and "season_losses" columns, and clear the "tier" and "division" columns so that players are placed from their new mmr, for every row in the profiles table.
+var psSoftResetProfiles = fmt.Sprintf("UPDATE `%v`.`%v` SET `mmr` = %v, `season_wins` = 0, `season_draws` = 0, `season_losses` = 0, `tier` = NULL, `division` = NULL WHERE `id` >= 100;", dbname, dbtableProfiles, softResetMMR)
+
+// Set the "rolled_over" column to the current time for the row in the seasons table with the specified ID.
+var psSetSeasonRolledOver = fmt.Sprintf("UPDATE `%v`.`%v` SET `rolled_over` = NOW() WHERE `id` = ?;", dbname, dbtableSeasons)
+
+// Get the number of rows in the season standings table for the specified season.
+var psGetSeasonStandingsCount = fmt.Sprintf("SELECT COUNT(*) FROM `%v`.`%v` WHERE `season` = ?;", dbname, dbtableStandings)
+
+// Get the "handle" and "public_id" (as "pid") from the users table, "avatar" from the profiles table, and the "mmr", "wins", "draws", "losses", a generated win ratio and total, and "final_rank" from the season standings table, for a range of the standings for the specified season, ordered by rank.
+var psGetSeasonLeaderboards = fmt.Sprintf("SELECT `u`.`handle`, `p`.`avatar`, `s`.`mmr`, `s`.`wins`, `s`.`draws`, `s`.`losses`, `s`.`wins` / (`s`.`wins` + `s`.`draws` + `s`.`losses`), `s`.`wins` + `s`.`draws` + `s`.`losses`, `u`.`public_id` AS `pid`, `s`.`final_rank` FROM `%[1]v`.`%[2]v` `s` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `s`.`player` JOIN `%[1]v`.`%[4]v` `p` on `p`.`id` = `s`.`player` WHERE `s`.`season` = ? ORDER BY `s`.`final_rank`, `s`.`player` LIMIT ? OFFSET ?;", dbname, dbtableStandings, dbtableUsers, dbtableProfiles)
+
+// Get the same columns as above, for the row in the season standings table for the specified season and the player with the specified public ID.
+var psGetSeasonIndividualRank = fmt.Sprintf("SELECT `u`.`handle`, `p`.`avatar`, `s`.`mmr`, `s`.`wins`, `s`.`draws`, `s`.`losses`, `s`.`wins` / (`s`.`wins` + `s`.`draws` + `s`.`losses`), `s`.`wins` + `s`.`draws` + `s`.`losses`, `u`.`public_id` AS `pid`, `s`.`final_rank` FROM `%[1]v`.`%[2]v` `s` JOIN `%[1]v`.`%[3]v` `u` on `u`.`id` = `s`.`player` JOIN `%[1]v`.`%[4]v` `p` on `p`.`id` = `s`.`player` WHERE `s`.`season` = ? AND `u`.`public_id` = ?;", dbname, dbtableStandings, dbtableUsers, dbtableProfiles)
+
+// Get the "final_rank", "mmr", "wins", "draws", and "losses" columns from the row in the season standings table for the specified season and player.
+var psGetSeasonStanding = fmt.Sprintf("SELECT `final_rank`, `mmr`, `wins`, `draws`, `losses` FROM `%v`.`%v` WHERE `season` = ? AND `player` = ?;", dbname, dbtableStandings)
+
+// GetSeasons returns every season, ordered by start time.
+func GetSeasons() (seasons []types.Season, err error) {
+
+	// Prepare a statement that will get all of the seasons. Exit early on error.
+	statement, err := db.Prepare(psGetSeasons)
+	if err != nil {
+		return seasons, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the seasons table.
+	rows, err := statement.Query()
+	if err != nil {
+		return seasons, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all the rows, scanning each into a new season. Exit early on error.
+	seasons = make([]types.Season, 0)
+	for rows.Next() {
+		season := types.Season{}
+		err = rows.Scan(&season.ID, &season.Name, &season.Start, &season.End, &season.RolledOver)
+		if err != nil {
+			return seasons, err
+		}
+
+		seasons = append(seasons, season)
+	}
+
+	return seasons, rows.Err()
+}
+
+// GetSeason returns the season with the specified ID.
+func GetSeason(seasonID uint64) (season types.Season, err error) {
+
+	// Prepare a statement that will get the season. Exit early on error.
+	statement, err := db.Prepare(psGetSeason)
+	if err != nil {
+		return season, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the seasons table for the season, and scan it into the return variable.
+	err = statement.QueryRow(seasonID).Scan(&season.ID, &season.Name, &season.Start, &season.End, &season.RolledOver)
+	if err == sql.ErrNoRows {
+		return season, errors.New("Season not found")
+	}
+
+	return season, err
+}
+
+// AddSeason schedules a new season, and returns its ID. Seasons can not overlap.
+func AddSeason(name string, start time.Time, end time.Time, createdBy uint64) (id uint64, err error) {
+
+	// As this database interaction has multiple steps, begin a transaction to protect against race conditions.
+	transaction, err := db.Begin()
+	if err != nil {
+		return id, err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will check whether the season overlaps with another. Exit early on error.
+	statement, err := transaction.Prepare(psCheckSeasonOverlap)
+	if err != nil {
+		return id, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the seasons table for overlapping seasons. Exit early on error, or if there is an overlapping season.
+	var overlaps bool
+	err = statement.QueryRow(end, start).Scan(&overlaps)
+	if err != nil {
+		return id, err
+	}
+
+	if overlaps {
+		return id, errors.New("Season overlaps another season")
+	}
+
+	// Prepare a statement that will add the season. Exit early on error.
+	statement, err = transaction.Prepare(psAddSeason)
+	if err != nil {
+		return id, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the database, adding the season. Exit early on error.
+	result, err := statement.Exec(name, start, end, createdBy)
+	if err != nil {
+		return id, err
+	}
+
+	// Get the ID of the new season. Exit early on error.
+	insertID, err := result.LastInsertId()
+	if err != nil {
+		return id, err
+	}
+
+	// Commit the transaction. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return id, err
+	}
+
+	return uint64(insertID), nil
+}
+
+// RolloverSeason ends the season with the specified ID. The final standings of every player that played in the season
+// are archived, and then every player's mmr is soft reset toward the target, keeping the specified fraction of the
+// difference (0 for a hard reset, and 1 for no reset), and their season wins, draws, and losses are reset. Each change
+// in mmr is recorded in the rating history. The season must have ended, and can only be rolled over once.
+func RolloverSeason(seasonID uint64, target int16, keep float64) (err error) {
+
+	// As this database interaction has multiple steps, begin a transaction so that the standings and resets are all
+	// applied, or none of them are.
+	transaction, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	// Defer rollback of this transaction so that it is cleaned up properly when this function exits.
+	defer transaction.Rollback()
+
+	// Prepare a statement that will get the state of the season, locking it so that it can't be rolled over twice.
+	// Exit early on error.
+	statement, err := transaction.Prepare(psGetSeasonState)
+	if err != nil {
+		return err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the seasons table for the season. Exit early on error, or if the season can't be rolled over.
+	var ended, rolledOver bool
+	err = statement.QueryRow(seasonID).Scan(&ended, &rolledOver)
+	if err == sql.ErrNoRows {
+		return errors.New("Season not found")
+	} else if err != nil {
+		return err
+	}
+
+	if !ended {
+		return errors.New("Season has not ended")
+	}
+
+	if rolledOver {
+		return errors.New("Season has already been rolled over")
+	}
+
+	// Execute each of the rollover steps in order. Exit early on error.
+	steps := []struct {
+		query string
+		args  []interface{}
+	}{
+		{query: psArchiveSeasonStandings, args: []interface{}{seasonID}},
+		{query: psAddSeasonRatingHistory, args: []interface{}{types.RatingChangeSeason, target, target, keep, target, target, keep}},
+		{query: psSoftResetProfiles, args: []interface{}{target, target, keep}},
+		{query: psSetSeasonRolledOver, args: []interface{}{seasonID}},
+	}
+
+	for _, step := range steps {
+		_, err = transaction.Exec(step.query, step.args...)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Commit the transaction, essentially finalizing all the changes that were just made. Exit early on error.
+	err = transaction.Commit()
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetSeasonLeaderboards returns the final standings for the specified season, starting at rank (start) returning
+// (count) results, as well as an extra blob containing the details for the user specified.
+func GetSeasonLeaderboards(seasonID uint64, publicID string, start uint64, count uint64) (leaderboards types.LeaderboardResponsePayload, err error) {
+	leaderboards.Queue = settings.QueueRanked
+
+	// Prepare a statement that will get the number of standings for the season. Exit early on error.
+	statement, err := db.Prepare(psGetSeasonStandingsCount)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the season standings table for the count. Exit early on error.
+	var standingsCount uint64
+	err = statement.QueryRow(seasonID).Scan(&standingsCount)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// If a public ID was provided, get the row specific to that user as well.
+	if publicID != "" {
+
+		// Prepare a statement that will get the standing for a specific user. Exit early on error.
+		statement, err = db.Prepare(psGetSeasonIndividualRank)
+		if err != nil {
+			return leaderboards, err
+		}
+
+		// Defer closing of the statement so that it is cleaned up properly when this function exits.
+		defer statement.Close()
+
+		// Query the standing for the user. Only report db errors, not found = silently return default values.
+		leaderboards.User, err = scanLeaderboardRow(statement.QueryRow(seasonID, publicID))
+		if err == nil {
+			leaderboards.User.OutOf = standingsCount
+			leaderboards.User.Tier, leaderboards.User.Division = placement("", 0, leaderboards.User.MMR)
+		} else if err != sql.ErrNoRows {
+			return leaderboards, err
+		}
+	}
+
+	// Prepare a statement that will get the range of standings. Exit early on error.
+	statement, err = db.Prepare(psGetSeasonLeaderboards)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the season standings table for the range.
+	rows, err := statement.Query(seasonID, count, start)
+	if err != nil {
+		return leaderboards, err
+	}
+
+	// Defer closing of the rows, so that the resource is released properly when the function exits.
+	defer rows.Close()
+
+	// Iterate over all of the rows, adding each to the return variable. Exit early on error.
+	leaderboards.Leaderboards = make([]types.LeaderboardRow, 0)
+	for rows.Next() {
+		row, err := scanLeaderboardRow(rows)
+		if err != nil {
+			return leaderboards, err
+		}
+
+		// Archived standings have no stored tier, so the player is placed from their final mmr.
+		row.OutOf = standingsCount
+		row.Tier, row.Division = placement("", 0, row.MMR)
+		leaderboards.Leaderboards = append(leaderboards.Leaderboards, row)
+	}
+
+	return leaderboards, rows.Err()
+}
+
+// scanLeaderboardRow scans a row from one of the season or queue leaderboards queries into a leaderboards row. The
+// tier, division, and whether the rating is provisional are left for the caller to set.
+func scanLeaderboardRow(scanner interface{ Scan(...interface{}) error }) (row types.LeaderboardRow, err error) {
+
+	// Note the edge case for the win ratio - it is null if no games were played, so it is scanned into temporary
+	// float32 pointer.
+	var winRatio *float32 = nil
+	err = scanner.Scan(
+		&row.Handle,
+		&row.Avatar,
+		&row.MMR,
+		&row.Wins,
+		&row.Draws,
+		&row.Losses,
+		&winRatio,
+		&row.RankedTotal,
+		&row.PublicID,
+		&row.Rank,
+	)
+
+	if winRatio != nil {
+		row.WinRatio = *winRatio
+	}
+
+	return row, err
+}
+
+// GetSeasonStanding returns the final standing in the specified season for the player with the specified database
+// ID, or nil if they did not play in the season.
+func GetSeasonStanding(seasonID uint64, databaseID uint64) (standing *types.SeasonStanding, err error) {
+
+	// Prepare a statement that will get the number of standings for the season. Exit early on error.
+	statement, err := db.Prepare(psGetSeasonStandingsCount)
+	if err != nil {
+		return standing, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the season standings table for the count. Exit early on error.
+	result := types.SeasonStanding{SeasonID: seasonID}
+	err = statement.QueryRow(seasonID).Scan(&result.OutOf)
+	if err != nil {
+		return standing, err
+	}
+
+	// Prepare a statement that will get the standing for the player. Exit early on error.
+	statement, err = db.Prepare(psGetSeasonStanding)
+	if err != nil {
+		return standing, err
+	}
+
+	// Defer closing of the statement so that it is cleaned up properly when this function exits.
+	defer statement.Close()
+
+	// Query the season standings table for the player. A player that did not play in the season has no standing.
+	err = statement.QueryRow(seasonID, databaseID).Scan(&result.Rank, &result.MMR, &result.Wins, &result.Draws, &result.Losses)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	} else if err != nil {
+		return standing, err
+	}
+
+	return &result, nil
+}
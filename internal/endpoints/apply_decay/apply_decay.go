@@ -0,0 +1,49 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package main implements interfaces for the lambda functions to be run, either through AWS
+// lambdas, or running locally.
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"time"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/routes"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// functionWrapper is used so that this file can easily be copied and converted for use with another scheduled handler.
+func functionWrapper(ctx context.Context, event events.CloudWatchEvent) (result types.DecayResult, err error) {
+	return routes.ApplyDecay(ctx, event)
+}
+
+func main() {
+
+	// Parse the command line flags.
+	local := flag.Bool("local", false, "apply decay once and exit, instead of starting the lambda function handler")
+	flag.Parse()
+
+	// Initialize the database package.
+	database.Init()
+
+	// When running locally, apply decay once with an event for the current time, and print the result.
+	if *local {
+		result, err := functionWrapper(context.Background(), events.CloudWatchEvent{Time: time.Now()})
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		log.Printf("checked %v players, decayed %v", result.Checked, result.Decayed)
+		return
+	}
+
+	// Start the lambda function handler.
+	lambda.Start(functionWrapper)
+}
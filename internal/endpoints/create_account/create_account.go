@@ -8,10 +8,15 @@ package main
 
 import (
 	"context"
+	"log"
+	"os"
+	"time"
 
 	"github.com/6a/blade-ii-api/internal/database"
 	"github.com/6a/blade-ii-api/internal/routes"
+	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/profanity"
 	"github.com/aws/aws-lambda-go/events"
 	"github.com/aws/aws-lambda-go/lambda"
 )
@@ -26,6 +31,13 @@ func main() {
 	// Initialize the database package.
 	database.Init()
 
+	// Configure the profanity filter with the embedded word lists, an optional word list file, and the profanity terms
+	// table. Errors are logged rather than fatal, as the filter still uses whichever sources loaded successfully.
+	err := profanity.Configure(time.Minute*settings.ProfanityDictionaryLifetime, profanity.Embedded(), profanity.File(os.Getenv("profanity_file")), profanity.SourceFunc(database.GetProfanityList))
+	if err != nil {
+		log.Printf("Failed to load profanity word lists: %v", err)
+	}
+
 	// Start the lambda function handler.
 	lambda.Start(functionWrapper)
 }
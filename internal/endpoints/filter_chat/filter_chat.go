@@ -0,0 +1,43 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package main implements interfaces for the lambda functions to be run, either through AWS
+// lambdas, or running locally.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/routes"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/profanity"
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+// functionWrapper is used so that this file can easily be copied and converted for use with another route.
+func functionWrapper(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+	return routes.FilterChat(ctx, request)
+}
+
+func main() {
+
+	// Initialize the database package.
+	database.Init()
+
+	// Configure the profanity filter with the embedded word lists, an optional word list file, and the profanity terms
+	// table. Errors are logged rather than fatal, as the filter still uses whichever sources loaded successfully.
+	err := profanity.Configure(time.Minute*settings.ProfanityDictionaryLifetime, profanity.Embedded(), profanity.File(os.Getenv("profanity_file")), profanity.SourceFunc(database.GetProfanityList))
+	if err != nil {
+		log.Printf("Failed to load profanity word lists: %v", err)
+	}
+
+	// Start the lambda function handler.
+	lambda.Start(functionWrapper)
+}
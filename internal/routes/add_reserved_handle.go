@@ -0,0 +1,103 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// AddReservedHandle adds an entry to the reserved handles store, using the details specified in the message body
+// { handle: {String}, match: {Number}, reservedfor: {String} }. The match type is 0 for exact, 1 for prefix, and 2 for a
+// regular expression. If reservedfor is specified, it should be the public ID of the only account allowed to use the handle.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func AddReservedHandle(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Extract the username and password from the Authorization header.
+	handle, password, err := auth.ExtractCredentials(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the account specified user has the required privilege level to perform this action.
+	// Note that this is done before the credentials check, as the credentials check is fairly slow and being able to
+	// exit early should reduce server load.
+	hasRequiredPrivilege, err := database.HasRequiredPrivilege(handle, database.ServerAdminPrivilege)
+	if err != nil || !hasRequiredPrivilege {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check to see if the parsed username and password are valid.
+	err = database.ValidateCredentials(handle, password)
+	if err != nil {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Attempt to parse the request body as a ReservedHandleRequest struct.
+	rhr := types.ReservedHandleRequest{}
+	err = json.Unmarshal([]byte(request.Body), &rhr)
+	if err != nil {
+		r = packageGenericError(400, types.RequestMarshalError, err)
+		return r, nil
+	}
+
+	// Check to ensure that all the expected fields were present in the JSON
+	// body, with the correct format, type etc..
+	fieldsValid, code, info := validateRHRFields(rhr)
+	if !fieldsValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Check that the handle and match type can be stored.
+	reservedHandleValid, code, info := validateReservedHandle(*rhr.Handle, *rhr.Match)
+	if !reservedHandleValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// If the entry is to be reserved for a specific account, get the database ID for that account.
+	var reservedFor *uint64
+	if rhr.ReservedFor != nil {
+		databaseID, err := database.GetDatabaseID(*rhr.ReservedFor)
+		if err != nil {
+			r = packageGenericError(404, types.ReservedHandleReservedForNotFound, errors.New("Public ID not found"))
+			return r, nil
+		}
+
+		reservedFor = &databaseID
+	}
+
+	// Get the database ID for the caller, so that the entry can be attributed to them.
+	actorDatabaseID, _, err := database.GetIDs(handle)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Attempt to add the entry to the reserved handles store.
+	id, err := database.AddReservedHandle(*rhr.Handle, *rhr.Match, reservedFor, uint64(actorDatabaseID))
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Package the ID of the new entry in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, types.ReservedHandleResponsePayload{ID: id})
+
+	return r, nil
+}
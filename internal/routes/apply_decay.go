@@ -0,0 +1,66 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// decayPolicy decides how the ratings of inactive players decay, based on the settings.
+var decayPolicy = elo.DecayPolicy{Rules: settings.Decay}
+
+// ApplyDecay decays the ratings of players who have not played a ranked match recently, based on their tier. It is
+// intended to be triggered by a scheduled event (at least once a day), rather than through the REST API, and decays
+// each player's rating at most once a day, so it is safe to retry. Each change is recorded in the rating history.
+//
+// Unlike the REST routes, errors are returned, so that a failed run is reported and retried. Players decayed before the
+// error are not decayed again on the same day.
+func ApplyDecay(ctx context.Context, event events.CloudWatchEvent) (result types.DecayResult, err error) {
+
+	// Exit early if no tier decays, as there is nothing to do.
+	minGracePeriod, ok := decayPolicy.MinGracePeriod()
+	if !ok {
+		return result, nil
+	}
+
+	// Get every player who has been inactive for long enough that their rating might decay.
+	candidates, err := database.GetDecayCandidates(minGracePeriod)
+	if err != nil {
+		return result, err
+	}
+
+	result.Checked = len(candidates)
+
+	for _, candidate := range candidates {
+
+		// Decay the player's rating based on their current tier, skipping them if it doesn't change.
+		placement := matchStatsPlacement(candidate.MatchStats)
+		rating, decayed := decayPolicy.Decay(matchStatsRating(candidate.MatchStats), placement.Tier, candidate.Inactive)
+		if !decayed {
+			continue
+		}
+
+		// Demote the player if their decayed MMR warrants it, and save the change.
+		matchStats := candidate.MatchStats
+		setMatchStatsRating(&matchStats, rating)
+		setMatchStatsPlacement(&matchStats, placement)
+
+		err = database.DecayRating(candidate.DatabaseID, matchStats)
+		if err != nil {
+			return result, err
+		}
+
+		result.Decayed++
+	}
+
+	return result, nil
+}
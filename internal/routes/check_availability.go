@@ -0,0 +1,137 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/internal/validation"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const queryParamHandle string = "handle"
+const queryParamEmail string = "email"
+
+// CheckAvailability checks whether the handle specified by the (handle) query param, and optionally the email address
+// specified by the (email) query param, could be used to create an account. Every rule that CreateAccount would check
+// is run, without hashing a password or creating anything, and every failing rule is returned with its result code.
+//
+// Requests are rate limited per IP address, so that this route cannot be used to enumerate handles or emails in bulk.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func CheckAvailability(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Attempt to fetch each query param:
+	// - handle: string
+	// - email: string [ optional ]
+
+	// Check for the existence of, and then get the value for the "handle" query parameter.
+	var handle string
+	if _, ok := request.QueryStringParameters[queryParamHandle]; ok {
+		handle = request.QueryStringParameters[queryParamHandle]
+	} else {
+		r = packageGenericError(400, types.HandleMissingOrWrongType, errors.New("'handle' query param missing"))
+		return r, nil
+	}
+
+	// Check the rate limit for the caller's IP address before doing anything else, as the checks below hit the database.
+	allowed, err := database.ConsumeRateLimit(fmt.Sprintf("availability:%v", request.RequestContext.Identity.SourceIP), settings.AvailabilityRateLimit, time.Second*settings.AvailabilityRateLimitWindow)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	if !allowed {
+		r = packageGenericError(429, types.RateLimitExceeded, errors.New("Too many requests - please try again later"))
+		return r, nil
+	}
+
+	// Run each check, keeping every failure rather than exiting at the first one.
+	failures := make([]types.AvailabilityFailure, 0)
+	check := func(valid bool, code types.B2ResultCode, info string) {
+		if !valid {
+			failures = append(failures, types.AvailabilityFailure{Code: code, Info: info})
+		}
+	}
+
+	check(validateHandleLength(handle))
+	check(validateHandleFormat(handle))
+	check(validateHandleNotRude(handle))
+
+	// Get the reserved handles, and ensure that the handle does not match any of them. A database ID of 0 is used
+	// as the account does not exist yet, so no reservation can be assigned to it.
+	reservedHandles, err := database.GetReservedHandles()
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	check(validateHandleNotReserved(handle, 0, reservedHandles))
+
+	// Get the handles that are protected from impersonation, and ensure that the handle is not confusable with any of them.
+	protectedHandles, err := database.GetProtectedHandles(settings.ProtectedLeaderboardSize)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	check(validateHandleNotImpersonating(handle, protectedHandles))
+
+	// Check that the handle is not already in use.
+	handleInUse, err := database.HandleInUse(handle)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	check(!handleInUse, types.HandleAlreadyInUse, "Handle already in use")
+
+	// If an email address was specified, check its format, that it is not disposable, and that neither it nor an
+	// equivalent address is already in use.
+	if email, ok := request.QueryStringParameters[queryParamEmail]; ok {
+		check(validateEmailFormat(email))
+
+		emailDomainBlocked, err := database.EmailDomainBlocked(validation.EmailDomain(email))
+		if err != nil {
+			r = packageGenericError(500, types.DatabaseError, err)
+			return r, nil
+		}
+
+		check(validateEmailNotDisposable(email, emailDomainBlocked))
+
+		emailInUse, exact, err := database.EmailInUse(email)
+		if err != nil {
+			r = packageGenericError(500, types.DatabaseError, err)
+			return r, nil
+		}
+
+		// Report collisions with the same codes as CreateAccount, so that the client sees the same result from both.
+		if exact {
+			check(false, types.EmailAlreadyInUse, "Email address already in use")
+		} else {
+			check(!emailInUse, types.EmailEquivalentInUse, "An equivalent email address is already in use")
+		}
+	}
+
+	// Create a message body containing the return data for this API call - in this case whether or not the
+	// details are available, and every rule that they failed.
+	availabilityResponse := types.AvailabilityResponsePayload{
+		Available: len(failures) == 0,
+		Failures:  failures,
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, availabilityResponse)
+
+	return r, nil
+}
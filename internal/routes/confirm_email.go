@@ -0,0 +1,50 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const queryParamToken string = "token"
+
+// ConfirmEmail confirms the email address for the account with the email confirmation token specified by the (token)
+// query param - the token that was sent to the email address when the account was created.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func ConfirmEmail(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Check for the existence of, and then get the value for the "token" query parameter.
+	token := request.QueryStringParameters[queryParamToken]
+	if token == "" {
+		r = packageGenericError(400, types.ConfirmEmailTokenMissing, errors.New("'token' query param missing"))
+		return r, nil
+	}
+
+	// Attempt to confirm the email address for the account with the token.
+	confirmed, err := database.ConfirmEmail(token)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// The token was not found, or has expired (or has already been used).
+	if !confirmed {
+		r = packageGenericError(404, types.ConfirmEmailTokenInvalid, errors.New("Email confirmation token is invalid or has expired"))
+		return r, nil
+	}
+
+	// Package an empty string in a lambda response - note the status code of 204, a success with no message body.
+	r = types.MakeLambdaResponse(204, types.Success, "")
+
+	return r, nil
+}
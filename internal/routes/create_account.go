@@ -8,12 +8,14 @@ package routes
 import (
 	"context"
 	"encoding/json"
-	"errors"
+	"log"
+	"time"
 
 	"github.com/6a/blade-ii-api/internal/database"
 	"github.com/6a/blade-ii-api/internal/email"
+	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
-	"github.com/6a/blade-ii-api/pkg/profanity"
+	"github.com/6a/blade-ii-api/internal/validation"
 	"github.com/aws/aws-lambda-go/events"
 )
 
@@ -39,6 +41,16 @@ func CreateAccount(ctx context.Context, request events.APIGatewayProxyRequest) (
 		return r, nil
 	}
 
+	// Check that the proof of work challenge was solved, before doing anything else that touches the database. The
+	// challenge must have been issued to the same IP address that is creating the account. It is only redeemed once the
+	// rest of the request is valid, so that a typo or a taken handle does not waste the client's solution.
+	ip := request.RequestContext.Identity.SourceIP
+	challengeSolved, status, code, info := validateSignupChallenge(ip, *ucr.Challenge, *ucr.Nonce, false)
+	if !challengeSolved {
+		r = types.MakeLambdaResponse(status, code, info)
+		return r, nil
+	}
+
 	// The following validation functions ensure that the provided details are appropriate within the
 	// context of this app - performing length, format, profanity checks etc..
 
@@ -60,15 +72,68 @@ func CreateAccount(ctx context.Context, request events.APIGatewayProxyRequest) (
 		return r, nil
 	}
 
+	// Check whether the email domain has been blocked, and ensure that it is not disposable.
+	emailDomainBlocked, err := database.EmailDomainBlocked(validation.EmailDomain(*ucr.Email))
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	emailNotDisposable, code, info := validateEmailNotDisposable(*ucr.Email, emailDomainBlocked)
+	if !emailNotDisposable {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
 	passwordLengthValid, code, info := validatePasswordFormat(*ucr.Password)
 	if !passwordLengthValid {
 		r = types.MakeLambdaResponse(400, code, info)
 		return r, nil
 	}
 
-	rude := profanity.ContainsProfanity(*ucr.Handle)
-	if rude {
-		r = packageGenericError(400, types.HandleRude, errors.New("Handle contains profanity"))
+	// Handles are public, so any profanity that is more severe than a flag is rejected. Flagged handles are allowed,
+	// but logged so that they can be reviewed.
+	handleNotRude, code, info := validateHandleNotRude(*ucr.Handle)
+	if !handleNotRude {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	} else if info != "" {
+		log.Printf("Handle [ %v ] flagged for review - %v", *ucr.Handle, info)
+	}
+
+	// Get the reserved handles, and ensure that the new handle does not match any of them. A database ID of 0 is used
+	// as the account does not exist yet, so no reservation can be assigned to it.
+	reservedHandles, err := database.GetReservedHandles()
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	handleNotReserved, code, info := validateHandleNotReserved(*ucr.Handle, 0, reservedHandles)
+	if !handleNotReserved {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Get the handles that are protected from impersonation, and ensure that the new handle is not confusable
+	// with any of them.
+	protectedHandles, err := database.GetProtectedHandles(settings.ProtectedLeaderboardSize)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	handleNotImpersonating, code, info := validateHandleNotImpersonating(*ucr.Handle, protectedHandles)
+	if !handleNotImpersonating {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Redeem the challenge now that everything else has been checked, so that each challenge can only be used to create
+	// a single account.
+	challengeRedeemed, status, code, info := validateSignupChallenge(ip, *ucr.Challenge, *ucr.Nonce, true)
+	if !challengeRedeemed {
+		r = types.MakeLambdaResponse(status, code, info)
 		return r, nil
 	}
 
@@ -80,6 +145,13 @@ func CreateAccount(ctx context.Context, request events.APIGatewayProxyRequest) (
 		return r, nil
 	}
 
+	// Count the new account towards the signup challenge difficulty for the IP address. Failure is only logged, as the
+	// account has already been created.
+	_, err = database.IncrementRateLimit(signupRateLimitKey(ip), time.Second*settings.SignupWindow)
+	if err != nil {
+		log.Printf("Failed to count signup for [ %v ] - %v", ip, err)
+	}
+
 	// Send the email confirmation to the address specified.
 	err = email.SendEmailConfirmation(*ucr.Email, *ucr.Handle, emailConfirmationToken)
 	if err != nil {
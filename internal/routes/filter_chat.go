@@ -0,0 +1,111 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/profanity"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// chatFilterToken is the token that callers of the FilterChat route (such as the game server) must present as a Bearer
+// token, read from the environment variables.
+var chatFilterToken = os.Getenv("chat_filter_token")
+
+// FilterChat masks profanity in a batch of chat lines, specified in the message body { lines: [{String}], private: {Boolean} },
+// and returns the masked lines along with the spans that matched in each. Only callers with the shared chat filter token
+// (such as the game server) may use this route. The token check is cheap, so lines can be filtered as they are sent.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func FilterChat(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Without a token, any caller could use the route, so refuse every request.
+	if chatFilterToken == "" {
+		r = packageGenericError(500, types.ChatFilterUnavailable, errors.New("Chat filtering is not configured"))
+		return r, nil
+	}
+
+	// Extract the token from the Authorization header.
+	token, err := auth.ExtractBearerToken(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the token is the chat filter token - this is a constant time compare, so that the token can't be
+	// guessed from the response time.
+	if subtle.ConstantTimeCompare([]byte(token), []byte(chatFilterToken)) == 0 {
+		r = packageGenericError(403, types.AuthTokenAuthFailed, errors.New("Chat filter token is incorrect"))
+		return r, nil
+	}
+
+	// Attempt to parse the message body into a chat filter request struct.
+	cfr := types.ChatFilterRequest{}
+	err = json.Unmarshal([]byte(request.Body), &cfr)
+	if err != nil {
+		r = packageGenericError(400, types.RequestMarshalError, err)
+		return r, nil
+	}
+
+	// Check to see if the request body format was valid.
+	fieldsValid, code, info := validateCFRFields(cfr)
+	if !fieldsValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Check that the batch is within the size limits.
+	linesValid, code, info := validateChatLines(*cfr.Lines)
+	if !linesValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Public chat has everything that is not allowed in public masked, while private chat only has blocked terms masked.
+	options := profanity.DefaultCensorOptions
+	if cfr.Private != nil && *cfr.Private {
+		options.Severity = profanity.Block
+	}
+
+	// Censor each line, converting the matched spans to their response format.
+	chatFilterResponse := types.ChatFilterResponsePayload{
+		Lines: make([]types.ChatFilterLine, 0, len(*cfr.Lines)),
+	}
+
+	for _, line := range *cfr.Lines {
+		censored, spans := profanity.Censor(line, options)
+
+		filtered := types.ChatFilterLine{
+			Text:  censored,
+			Spans: make([]types.ChatFilterSpan, 0, len(spans)),
+		}
+
+		for _, span := range spans {
+			filtered.Spans = append(filtered.Spans, types.ChatFilterSpan{
+				Start:    span.Start,
+				End:      span.End,
+				Severity: uint8(span.Severity),
+				Reason:   span.Reason,
+				Masked:   span.Masked,
+			})
+		}
+
+		chatFilterResponse.Lines = append(chatFilterResponse.Lines, filtered)
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, chatFilterResponse)
+
+	return r, nil
+}
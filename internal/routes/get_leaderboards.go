@@ -11,6 +11,7 @@ import (
 	"strconv"
 
 	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
 	"github.com/aws/aws-lambda-go/events"
 )
@@ -18,11 +19,15 @@ import (
 const queryParamFrom string = "from"
 const queryParamCount string = "count"
 const queryParamPublicID string = "pid"
+const queryParamSeason string = "season"
+const queryParamQueue string = "queue"
 const maxResultsSize uint64 = 100
 
 // GetLeaderboards returns the leaderboard data for the range specified in the query param (from, count), with an
 // extra member containing the row for the user specified by the (pid) public ID query param. If pid is unspecified,
-// the user member is returned with default values.
+// the user member is returned with default values. If the (queue) query param is specified, the ladder for that queue
+// is returned, rather than the ranked ladder. If the (season) query param is specified, the archived final standings
+// for that season are returned instead - seasons only apply to the ranked queue.
 //
 // Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
 // code (RFC 7231).
@@ -32,6 +37,8 @@ func GetLeaderboards(ctx context.Context, request events.APIGatewayProxyRequest)
 	// - from: uint64
 	// - count: uint64
 	// - pid: string [ optional ]
+	// - queue: string [ optional ]
+	// - season: uint64 [ optional ]
 
 	// Check for the existence of, and then get the value for the "from" query parameter.
 	var from string
@@ -81,6 +88,46 @@ func GetLeaderboards(ctx context.Context, request events.APIGatewayProxyRequest)
 		pid = ""
 	}
 
+	// Get the queue from the "queue" query parameter, which defaults to the ranked queue.
+	queue, ok, r := getQueue(request)
+	if !ok {
+		return r, nil
+	}
+
+	// If the "season" query parameter was specified, return the archived final standings for the season.
+	if season, ok := request.QueryStringParameters[queryParamSeason]; ok {
+		if queue != settings.QueueRanked {
+			r = packageGenericError(400, types.QueueSeasonUnsupported, errors.New("Seasons only apply to the ranked queue"))
+			return r, nil
+		}
+
+		seasonID, ok, r := getArchivedSeason(season)
+		if !ok {
+			return r, nil
+		}
+
+		leaderboards, err := database.GetSeasonLeaderboards(seasonID, pid, fromInt, countInt)
+		if err != nil {
+			r = packageLeaderboardsError(err)
+			return r, nil
+		}
+
+		r = types.MakeLambdaResponse(200, types.Success, leaderboards)
+		return r, nil
+	}
+
+	// If an unranked queue was specified, return the ladder for the queue.
+	if queue != settings.QueueRanked {
+		leaderboards, err := database.GetQueueLeaderboards(queue, pid, fromInt, countInt)
+		if err != nil {
+			r = packageLeaderboardsError(err)
+			return r, nil
+		}
+
+		r = types.MakeLambdaResponse(200, types.Success, leaderboards)
+		return r, nil
+	}
+
 	// Attempt to get the leaderboards data for the specified range. The return value will also have an extra member
 	// for the user's leaderboard row, if the pid was valid. This will be passed directly into the lambda response
 	// make function, to be packaged as a JSON string in the message body.
@@ -0,0 +1,104 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const queryParamPublicIDs string = "pids"
+
+// GetMatchQuality returns the predicted outcome of a match between each pair of the players specified by the comma
+// separated public IDs in the (pids) query param - the chance of each player winning, the change in MMR for both
+// players if either player won or the match was drawn, and how fair the match would be, from 0 to 1. Between 2 and
+// settings.MatchQualityMaxPlayers players can be compared at once.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func GetMatchQuality(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Check for the existence of, and then get the value for the "pids" query parameter.
+	pids, ok := request.QueryStringParameters[queryParamPublicIDs]
+	if !ok {
+		r = packageGenericError(400, types.MatchQualityPublicIDsMissing, errors.New("'pids' query param missing"))
+		return r, nil
+	}
+
+	// Split the public IDs, and check that there are a valid number of them, without duplicates.
+	publicIDs := strings.Split(pids, ",")
+	if len(publicIDs) < 2 || len(publicIDs) > settings.MatchQualityMaxPlayers {
+		r = packageGenericError(400, types.MatchQualityPublicIDsInvalid, fmt.Errorf("'pids' query param must contain between 2 and %v public IDs", settings.MatchQualityMaxPlayers))
+		return r, nil
+	}
+
+	seen := make(map[string]bool, len(publicIDs))
+	for i, pid := range publicIDs {
+		publicIDs[i] = strings.TrimSpace(pid)
+		if seen[publicIDs[i]] {
+			r = packageGenericError(400, types.MatchQualityPublicIDsInvalid, errors.New("'pids' query param contains duplicate public IDs"))
+			return r, nil
+		}
+
+		seen[publicIDs[i]] = true
+	}
+
+	// Get the rater for the configured rating system, so that the predictions match how the players would be rated.
+	rater, err := newRater(ratingSystem)
+	if err != nil {
+		r = packageGenericError(500, types.RatingSystemInvalid, err)
+		return r, nil
+	}
+
+	// Get the match stats for each player.
+	matchStats := make([]types.MatchStats, len(publicIDs))
+	for i, pid := range publicIDs {
+		databaseID, err := database.GetDatabaseID(pid)
+		if err != nil {
+			r = packageGenericError(404, types.MatchQualityPublicIDNotFound, fmt.Errorf("Public ID [ %v ] not found", pid))
+			return r, nil
+		}
+
+		matchStats[i], err = database.GetMatchStats(databaseID)
+		if err != nil {
+			r = packageGenericError(500, types.DatabaseError, err)
+			return r, nil
+		}
+	}
+
+	// Predict the outcome of a match between each pair of players.
+	payload := types.MatchQualityResponsePayload{Pairs: make([]types.MatchQualityPair, 0)}
+	for i := range publicIDs {
+		for j := i + 1; j < len(publicIDs); j++ {
+			player1Rating, player2Rating := matchStatsRating(matchStats[i]), matchStatsRating(matchStats[j])
+			player1WinChance := rater.Expected(player1Rating, player2Rating)
+
+			payload.Pairs = append(payload.Pairs, types.MatchQualityPair{
+				Player1:          publicIDs[i],
+				Player2:          publicIDs[j],
+				Player1WinChance: player1WinChance,
+				Player2WinChance: 1 - player1WinChance,
+				Quality:          elo.MatchQuality(rater, player1Rating, player2Rating),
+				Player1Win:       makeExpectedChange(rater, matchStats[i], matchStats[j], elo.Player1),
+				Draw:             makeExpectedChange(rater, matchStats[i], matchStats[j], elo.Draw),
+				Player2Win:       makeExpectedChange(rater, matchStats[i], matchStats[j], elo.Player2),
+			})
+		}
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, payload)
+
+	return r, nil
+}
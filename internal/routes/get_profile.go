@@ -10,13 +10,17 @@ import (
 	"errors"
 
 	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
 	"github.com/aws/aws-lambda-go/events"
 )
 
 const publicIDParameterKey = "pid"
 
-// GetProfile returns the profile data for the user specified by the public ID in the path /profiles/{publicID}.
+// GetProfile returns the profile data for the user specified by the public ID in the path /profiles/{publicID}. If the
+// (queue) query param is specified, the rating and w/d/l are from the ladder for that queue, rather than the ranked
+// ladder. If the (season) query param is specified, the user's final standing in that season is also returned - seasons
+// only apply to the ranked queue.
 //
 // Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
 // code (RFC 7231).
@@ -38,14 +42,45 @@ func GetProfile(ctx context.Context, request events.APIGatewayProxyRequest) (r t
 		return r, nil
 	}
 
-	// Attempt to get the profile data for the user. This will be passed directly into the lambda response
-	// make function, to be packaged as a JSON string in the message body.
-	profile, err := database.GetProfile(DBID)
+	// Get the queue from the "queue" query parameter, which defaults to the ranked queue.
+	queue, ok, r := getQueue(request)
+	if !ok {
+		return r, nil
+	}
+
+	// Attempt to get the profile data for the user, from the ladder for the queue. This will be passed directly into
+	// the lambda response make function, to be packaged as a JSON string in the message body.
+	var profile types.ProfileResponsePayload
+	if queue == settings.QueueRanked {
+		profile, err = database.GetProfile(DBID)
+	} else {
+		profile, err = database.GetQueueProfile(queue, DBID)
+	}
+
 	if err != nil {
 		r = packageProfileGetError(pid, err)
 		return r, nil
 	}
 
+	// If the "season" query parameter was specified, add the user's final standing in the season.
+	if season, ok := request.QueryStringParameters[queryParamSeason]; ok {
+		if queue != settings.QueueRanked {
+			r = packageGenericError(400, types.QueueSeasonUnsupported, errors.New("Seasons only apply to the ranked queue"))
+			return r, nil
+		}
+
+		seasonID, ok, r := getArchivedSeason(season)
+		if !ok {
+			return r, nil
+		}
+
+		profile.Season, err = database.GetSeasonStanding(seasonID, DBID)
+		if err != nil {
+			r = packageGenericError(500, types.DatabaseError, err)
+			return r, nil
+		}
+	}
+
 	// Package the return payload in a lambda response.
 	r = types.MakeLambdaResponse(200, types.Success, profile)
 
@@ -0,0 +1,99 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const queryParamPoints string = "points"
+
+// GetRatingHistory returns the rating history for the user specified by public ID in the path /ratings/{publicID}, in
+// chronological order, for graphing. By default, the first page of the history is returned. A range can be specified
+// with the (from) and (count) query params, or the entire history can be downsampled to a number of points specified
+// by the (points) query param, in which case (from) and (count) are ignored. The history is for the ranked queue, unless
+// another queue is specified with the (queue) query param.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func GetRatingHistory(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Check for the existence of, and then get the value for the "pid" path parameter.
+	pid, ok := request.PathParameters[publicIDParameterKey]
+	if !ok {
+		r = packageGenericError(400, types.RatingHistoryPublicIDMissing, errors.New("Public ID parameter missing"))
+		return r, nil
+	}
+
+	// Attempt to get the database ID for the user specified by public ID.
+	databaseID, err := database.GetDatabaseID(pid)
+	if err != nil {
+		r = packageGenericError(404, types.RatingHistoryPublicIDNotFound, errors.New("Public ID not found"))
+		return r, nil
+	}
+
+	// Get the queue from the "queue" query parameter, which defaults to the ranked queue.
+	queue, ok, r := getQueue(request)
+	if !ok {
+		return r, nil
+	}
+
+	// If the "points" query parameter was specified, return the entire history, downsampled.
+	if points, ok := request.QueryStringParameters[queryParamPoints]; ok {
+		pointsInt, err := strconv.ParseUint(points, 10, 64)
+		if err != nil || pointsInt == 0 || pointsInt > settings.RatingHistoryMaxCount {
+			r = packageGenericError(400, types.RatingHistoryPointsInvalid, errors.New("'points' query param invalid"))
+			return r, nil
+		}
+
+		history, err := database.GetRatingHistorySampled(databaseID, queue, pointsInt)
+		if err != nil {
+			r = packageGenericError(500, types.DatabaseError, err)
+			return r, nil
+		}
+
+		r = types.MakeLambdaResponse(200, types.Success, history)
+		return r, nil
+	}
+
+	// Otherwise, parse the optional "from" and "count" query parameters, which default to the first page.
+	fromInt, countInt := uint64(0), uint64(settings.RatingHistoryMaxCount)
+	if from, ok := request.QueryStringParameters[queryParamFrom]; ok {
+		fromInt, err = strconv.ParseUint(from, 10, 64)
+		if err != nil {
+			r = packageGenericError(400, types.RatingHistoryRangeFromInvalid, errors.New("'from' query param invalid"))
+			return r, nil
+		}
+	}
+
+	if count, ok := request.QueryStringParameters[queryParamCount]; ok {
+		countInt, err = strconv.ParseUint(count, 10, 64)
+		if err != nil || countInt > settings.RatingHistoryMaxCount {
+			r = packageGenericError(400, types.RatingHistoryRangeCountInvalid, errors.New("'count' query param invalid"))
+			return r, nil
+		}
+	}
+
+	// Attempt to get the range of the rating history. This will be passed directly into the lambda response make
+	// function, to be packaged as a JSON string in the message body.
+	history, err := database.GetRatingHistory(databaseID, queue, fromInt, countInt)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, history)
+
+	return r, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// GetReviewQueue returns every unresolved flag in the moderation review queue, oldest first. Pairs of players are
+// flagged when their matches against each other look like rating farming.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func GetReviewQueue(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Extract the username and password from the Authorization header.
+	handle, password, err := auth.ExtractCredentials(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the account specified user has the required privilege level to perform this action.
+	// Note that this is done before the credentials check, as the credentials check is fairly slow and being able to
+	// exit early should reduce server load.
+	hasRequiredPrivilege, err := database.HasRequiredPrivilege(handle, database.ServerAdminPrivilege)
+	if err != nil || !hasRequiredPrivilege {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check to see if the parsed username and password are valid.
+	err = database.ValidateCredentials(handle, password)
+	if err != nil {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Attempt to get the review queue.
+	queue, err := database.GetReviewQueue()
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, queue)
+
+	return r, nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/hashcash"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+var (
+
+	// signupChallengeSecret is the secret used to sign signup challenges, read from the environment variables. It must be
+	// the same for every lambda that issues or verifies signup challenges.
+	signupChallengeSecret = os.Getenv("signup_challenge_secret")
+
+	// signupChallengeIssuer issues and verifies signup challenges.
+	signupChallengeIssuer = hashcash.NewIssuer([]byte(signupChallengeSecret), time.Minute*settings.SignupChallengeLifetime)
+)
+
+// GetSignupChallenge returns a new proof of work challenge, which must be solved and submitted with a CreateAccount
+// request. The challenge is bound to the caller's IP address, and its difficulty increases with the number of accounts
+// that have been created from that IP address recently.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func GetSignupChallenge(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Without a secret, challenges could be forged, so refuse to issue them.
+	if signupChallengeSecret == "" {
+		r = packageGenericError(500, types.SignupChallengeUnavailable, errors.New("Signup challenges are not configured"))
+		return r, nil
+	}
+
+	// Determine the difficulty for the caller's IP address.
+	ip := request.RequestContext.Identity.SourceIP
+	difficulty, err := signupChallengeDifficulty(ip)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Issue a new challenge, bound to the caller's IP address.
+	challenge, expires, err := signupChallengeIssuer.Issue(ip, difficulty)
+	if err != nil {
+		r = packageGenericError(500, types.CryptoRandomError, err)
+		return r, nil
+	}
+
+	// Create a message body containing the return data for this API call - in this case the challenge, its
+	// difficulty, and when it expires.
+	signupChallengeResponse := types.SignupChallengeResponsePayload{
+		Challenge:  challenge,
+		Difficulty: difficulty,
+		Expires:    expires,
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, signupChallengeResponse)
+
+	return r, nil
+}
+
+// signupChallengeDifficulty returns the difficulty of signup challenges for the specified IP address, which increases
+// by one bit for every few accounts created from that IP address within the signup window.
+func signupChallengeDifficulty(ip string) (difficulty uint8, err error) {
+	signups, err := database.GetRateLimitCount(signupRateLimitKey(ip), time.Second*settings.SignupWindow)
+	if err != nil {
+		return difficulty, err
+	}
+
+	extra := signups / settings.SignupChallengeStep
+	if extra > settings.SignupChallengeMaxDifficulty-settings.SignupChallengeBaseDifficulty {
+		return settings.SignupChallengeMaxDifficulty, nil
+	}
+
+	return uint8(settings.SignupChallengeBaseDifficulty + extra), nil
+}
+
+// signupChallengeSpender records redeemed signup challenges in the rate limits table, so that each solved challenge can
+// only be used to create a single account.
+type signupChallengeSpender struct{}
+
+// Spend records the signup challenge with the specified ID as redeemed, and returns false if it already was. The record
+// is kept for the lifetime of a challenge, after which the challenge will have expired anyway.
+func (signupChallengeSpender) Spend(id string, expires time.Time) (ok bool, err error) {
+	count, err := database.IncrementRateLimit(fmt.Sprintf("signup_challenge:%v", id), time.Minute*settings.SignupChallengeLifetime)
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
+
+// signupRateLimitKey returns the rate limits key used to count account creations from the specified IP address.
+func signupRateLimitKey(ip string) string {
+	return fmt.Sprintf("signup:%v", ip)
+}
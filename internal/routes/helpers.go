@@ -6,11 +6,27 @@
 package routes
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
 	"github.com/6a/blade-ii-api/internal/validation"
+	"github.com/6a/blade-ii-api/pkg/confusables"
+	"github.com/6a/blade-ii-api/pkg/elo"
+	"github.com/6a/blade-ii-api/pkg/hashcash"
+	"github.com/6a/blade-ii-api/pkg/profanity"
+	"github.com/aws/aws-lambda-go/events"
 )
 
 // packageGenericError creates a lambda that will result in a HTTP response with the specified HTTP status code. The
@@ -84,6 +100,14 @@ func validateUCRFields(target types.UserCreationRequest) (ok bool, code types.B2
 		field = "password"
 		code = types.PasswordMissingOrWrongType
 		expectedType = "string"
+	} else if target.Challenge == nil {
+		field = "challenge"
+		code = types.SignupChallengeMissingOrWrongType
+		expectedType = "string"
+	} else if target.Nonce == nil {
+		field = "nonce"
+		code = types.SignupChallengeNonceMissingOrWrongType
+		expectedType = "string"
 	} else {
 
 		// If there was no error, set the return boolean to true, so the caller is aware that the specified update
@@ -135,6 +159,165 @@ func validateAURFields(target types.AvatarUpdateRequest) (ok bool, code types.B2
 	return ok, code, info
 }
 
+// validatePURFields returns true if the fields in a privilege update request are valid. If null, this would
+// suggest that the JSON string parsing process failed, due to a field being missage or of an incorrect type.
+// Returns true when the request is considered to be valid, and returns a result code and some relevant info
+// if invalid. Note that the password confirmation is optional, so it is not checked here.
+func validatePURFields(target types.PrivilegeUpdateRequest) (ok bool, code types.B2ResultCode, info string) {
+
+	// Declare some variables to store the field name and type, for building the info string
+	// when an error is detected.
+	var field string
+	var expectedType string
+
+	// Check each struct member to see if they are nil - which would indicate that there was an error, and
+	// the request is invalid. Set valus for the error code, as well as field and expected type.
+	if target.Privilege == nil {
+		field = "privilege"
+		code = types.PrivilegeUpdatePrivilegeMissingOrWrongType
+		expectedType = "uint8"
+	} else {
+
+		// If there was no error, set the return boolean to true, so the caller is aware that the specified update
+		// request was valid.
+		ok = true
+	}
+
+	// If the field variable has a value, then there was at least one error - so create the info string to be returned.
+	if len(field) != 0 {
+		info = fmt.Sprintf("Field (%v of type %v) not found, or could not be parsed due to incorrect typing", field, expectedType)
+	}
+
+	return ok, code, info
+}
+
+// validateRHRFields returns true if the fields in a reserved handle request are valid. If null, this would
+// suggest that the JSON string parsing process failed, due to a field being missage or of an incorrect type.
+// Returns true when the request is considered to be valid, and returns a result code and some relevant info
+// if invalid. Note that reservedfor is optional, so it is not checked here.
+func validateRHRFields(target types.ReservedHandleRequest) (ok bool, code types.B2ResultCode, info string) {
+
+	// Declare some variables to store the field name and type, for building the info string
+	// when an error is detected.
+	var field string
+	var expectedType string
+
+	// Check each struct member to see if they are nil - which would indicate that there was an error, and
+	// the request is invalid. Set valus for the error code, as well as field and expected type.
+	if target.Handle == nil {
+		field = "handle"
+		code = types.ReservedHandleHandleMissingOrWrongType
+		expectedType = "string"
+	} else if target.Match == nil {
+		field = "match"
+		code = types.ReservedHandleMatchMissingOrWrongType
+		expectedType = "uint8"
+	} else {
+
+		// If there was no error, set the return boolean to true, so the caller is aware that the specified update
+		// request was valid.
+		ok = true
+	}
+
+	// If the field variable has a value, then there was at least one error - so create the info string to be returned.
+	if len(field) != 0 {
+		info = fmt.Sprintf("Field (%v of type %v) not found, or could not be parsed due to incorrect typing", field, expectedType)
+	}
+
+	return ok, code, info
+}
+
+// validateSRFields returns true if the fields in a season request are valid. If null, this would
+// suggest that the JSON string parsing process failed, due to a field being missage or of an incorrect type.
+// Returns true when the request is considered to be valid, and returns a result code and some relevant info
+// if invalid.
+func validateSRFields(target types.SeasonRequest) (ok bool, code types.B2ResultCode, info string) {
+
+	// Declare some variables to store the field name and type, for building the info string
+	// when an error is detected.
+	var field string
+	var expectedType string
+
+	// Check each struct member to see if they are nil - which would indicate that there was an error, and
+	// the request is invalid. Set valus for the error code, as well as field and expected type.
+	if target.Name == nil {
+		field = "name"
+		code = types.SeasonNameMissingOrWrongType
+		expectedType = "string"
+	} else if target.Start == nil {
+		field = "start"
+		code = types.SeasonStartMissingOrWrongType
+		expectedType = "RFC 3339 timestamp"
+	} else if target.End == nil {
+		field = "end"
+		code = types.SeasonEndMissingOrWrongType
+		expectedType = "RFC 3339 timestamp"
+	} else {
+
+		// If there was no error, set the return boolean to true, so the caller is aware that the specified update
+		// request was valid.
+		ok = true
+	}
+
+	// If the field variable has a value, then there was at least one error - so create the info string to be returned.
+	if len(field) != 0 {
+		info = fmt.Sprintf("Field (%v of type %v) not found, or could not be parsed due to incorrect typing", field, expectedType)
+	}
+
+	return ok, code, info
+}
+
+// validateCFRFields returns true if the fields in a chat filter request are valid. If null, this would
+// suggest that the JSON string parsing process failed, due to a field being missage or of an incorrect type.
+// Returns true when the request is considered to be valid, and returns a result code and some relevant info
+// if invalid. Note that private is optional, so it is not checked here.
+func validateCFRFields(target types.ChatFilterRequest) (ok bool, code types.B2ResultCode, info string) {
+
+	// Declare some variables to store the field name and type, for building the info string
+	// when an error is detected.
+	var field string
+	var expectedType string
+
+	// Check each struct member to see if they are nil - which would indicate that there was an error, and
+	// the request is invalid. Set valus for the error code, as well as field and expected type.
+	if target.Lines == nil {
+		field = "lines"
+		code = types.ChatFilterLinesMissingOrWrongType
+		expectedType = "[]string"
+	} else {
+
+		// If there was no error, set the return boolean to true, so the caller is aware that the specified update
+		// request was valid.
+		ok = true
+	}
+
+	// If the field variable has a value, then there was at least one error - so create the info string to be returned.
+	if len(field) != 0 {
+		info = fmt.Sprintf("Field (%v of type %v) not found, or could not be parsed due to incorrect typing", field, expectedType)
+	}
+
+	return ok, code, info
+}
+
+// validateChatLines returns true if the number of lines in a chat filter request, and the length of each line, are
+// within the limits for this application.
+func validateChatLines(lines []string) (valid bool, code types.B2ResultCode, info string) {
+
+	// Check that there are not too many lines.
+	if len(lines) > settings.ChatFilterMaxLines {
+		return false, types.ChatFilterTooManyLines, fmt.Sprintf("Too many lines - the maximum is %v", settings.ChatFilterMaxLines)
+	}
+
+	// Check that each line is not too long. Note that the length is checked in runes, as the spans in the response are.
+	for i, line := range lines {
+		if utf8.RuneCountInString(line) > settings.ChatFilterMaxLineLength {
+			return false, types.ChatFilterLineTooLong, fmt.Sprintf("Line %v is too long - the maximum length is %v", i, settings.ChatFilterMaxLineLength)
+		}
+	}
+
+	return true, code, info
+}
+
 // validateMMRUpdateFields returns true if a handle meets the requirements for this application.
 func validateHandleLength(handle string) (valid bool, code types.B2ResultCode, info string) {
 
@@ -227,6 +410,286 @@ func validateHandleFormat(handle string) (valid bool, code types.B2ResultCode, i
 	return valid, code, info
 }
 
+// validateSignupChallenge returns true if the nonce solves the signup challenge, and the challenge was issued to the
+// specified IP address, has not expired, and is at least as difficult as the IP address's current difficulty. If redeem
+// is true, the challenge must also not have been used before, and is recorded as used so it can't be redeemed again -
+// this should only be done once the rest of the request is known to be valid, so that a rejected request does not waste
+// the client's solution. If the challenge could not be checked or recorded, a 500 status is returned along with the
+// code, otherwise the status is 400.
+func validateSignupChallenge(ip string, challenge string, nonce string, redeem bool) (valid bool, status types.HTTPCode, code types.B2ResultCode, info string) {
+
+	// Without a secret, challenges could be forged, so nothing can be verified.
+	if signupChallengeSecret == "" {
+		return false, 500, types.SignupChallengeUnavailable, "Signup challenges are not configured"
+	}
+
+	// The difficulty may have increased since the challenge was issued, in which case the challenge is too easy.
+	difficulty, err := signupChallengeDifficulty(ip)
+	if err != nil {
+		return false, 500, types.DatabaseError, err.Error()
+	}
+
+	if redeem {
+		err = signupChallengeIssuer.Redeem(ip, challenge, nonce, difficulty, signupChallengeSpender{})
+	} else {
+		err = signupChallengeIssuer.VerifyDifficulty(ip, challenge, nonce, difficulty)
+	}
+
+	switch err {
+	case nil:
+		return true, status, code, info
+	case hashcash.ErrExpired:
+		return false, 400, types.SignupChallengeExpired, "Signup challenge has expired - please request a new one"
+	case hashcash.ErrSolution:
+		return false, 400, types.SignupChallengeSolutionIncorrect, "Signup challenge nonce is incorrect"
+	case hashcash.ErrSpent:
+		return false, 400, types.SignupChallengeAlreadyUsed, "Signup challenge has already been used - please request a new one"
+	case hashcash.ErrEasy:
+		return false, 400, types.SignupChallengeDifficultyTooLow, "Signup challenge is no longer difficult enough - please request a new one"
+	case hashcash.ErrMalformed, hashcash.ErrSignature:
+		return false, 400, types.SignupChallengeInvalid, fmt.Sprintf("Signup challenge is invalid - %v", err)
+	default:
+		return false, 500, types.DatabaseError, err.Error()
+	}
+}
+
+// validateHandleNotRude returns true if the handle does not contain profanity. Handles are public, so any profanity
+// that is more severe than a flag is rejected. Flagged handles are valid, but the info describes why the handle was
+// flagged, so that the caller can log it for review.
+func validateHandleNotRude(handle string) (valid bool, code types.B2ResultCode, info string) {
+	rude, match := profanity.ContainsProfanity(handle)
+	if rude && match.Severity != profanity.Flag {
+		return false, types.HandleRude, fmt.Sprintf("Handle contains profanity - %v", match.Reason)
+	} else if rude {
+		return true, code, match.Reason
+	}
+
+	return true, code, info
+}
+
+// validateHandleNotImpersonating returns true if the specified handle is not visually confusable with any of the
+// specified protected handles (see database.GetProtectedHandles).
+func validateHandleNotImpersonating(handle string, protectedHandles []string) (valid bool, code types.B2ResultCode, info string) {
+
+	// Determine the skeleton for the handle - any protected handle with the same skeleton is confusable with it.
+	skeleton := confusables.Skeleton(handle)
+
+	// Check the skeleton against each of the protected handles.
+	for _, protectedHandle := range protectedHandles {
+		if confusables.Skeleton(protectedHandle) == skeleton {
+			code = types.HandleImpersonation
+			info = "Handle is too similar to the handle of a staff member, reserved account, or top ranked player"
+			return false, code, info
+		}
+	}
+
+	return true, code, info
+}
+
+// validateHandleNotReserved returns true if the specified handle does not match any of the specified reserved handles,
+// or if every entry that it matches is reserved for the account with the specified database ID. New accounts should pass
+// a database ID of 0, as no reservation can be assigned to them yet.
+//
+// Exact and prefix entries are compared using confusable skeletons, so that look-alike handles are also caught. Pattern
+// entries are regular expressions, and are matched against the canonical key for the handle.
+func validateHandleNotReserved(handle string, databaseID uint64, reserved []types.ReservedHandle) (valid bool, code types.B2ResultCode, info string) {
+
+	// Determine the skeleton and canonical key for the handle once, rather than for each entry.
+	skeleton := confusables.Skeleton(handle)
+	key := validation.CanonicalHandle(handle)
+
+	// Check the handle against each entry.
+	for _, entry := range reserved {
+
+		// Skip any entries that are reserved for the account that is being checked.
+		if entry.ReservedFor != nil && *entry.ReservedFor == databaseID {
+			continue
+		}
+
+		// Determine if the handle matches this entry, depending on the type of match.
+		var matched bool
+		switch entry.Match {
+		case types.ReservedHandleExact:
+			matched = confusables.Skeleton(entry.Handle) == skeleton
+		case types.ReservedHandlePrefix:
+			matched = strings.HasPrefix(skeleton, confusables.Skeleton(entry.Handle))
+		case types.ReservedHandlePattern:
+
+			// Patterns are validated when they are added, so an error here would suggest that the table was edited by
+			// hand - in which case the entry is skipped.
+			pattern, err := regexp.Compile(entry.Handle)
+			matched = err == nil && pattern.MatchString(key)
+		}
+
+		if matched {
+			code = types.HandleReserved
+			info = "Handle is reserved"
+			return false, code, info
+		}
+	}
+
+	return true, code, info
+}
+
+// validateSeason returns true if a season with the specified name, start, and end can be scheduled. The season must
+// end after it starts, and must not have ended already.
+func validateSeason(name string, start time.Time, end time.Time) (valid bool, code types.B2ResultCode, info string) {
+	nameLength := utf8.RuneCountInString(strings.TrimSpace(name))
+	if nameLength == 0 || nameLength > settings.SeasonNameMaxLength {
+		code = types.SeasonNameInvalid
+		info = fmt.Sprintf("Name must be between 1 and %v characters long", settings.SeasonNameMaxLength)
+		return false, code, info
+	}
+
+	if !end.After(start) || !end.After(time.Now()) {
+		code = types.SeasonDatesInvalid
+		info = "End must be after start, and in the future"
+		return false, code, info
+	}
+
+	return true, code, info
+}
+
+// getQueue returns the name of the queue specified by the (queue) query param, or the ranked queue if it is
+// unspecified. If the queue does not exist, a response describing the problem is returned, and ok is false.
+func getQueue(request events.APIGatewayProxyRequest) (queue string, ok bool, r types.LambdaResponse) {
+	queue, ok = request.QueryStringParameters[queryParamQueue]
+	if !ok {
+		return settings.QueueRanked, true, r
+	}
+
+	if valid, code, info := validateQueue(queue); !valid {
+		return queue, false, types.MakeLambdaResponse(400, code, info)
+	}
+
+	return queue, true, r
+}
+
+// validateQueue returns true if a queue with the specified name exists - either the ranked queue, or one of the
+// unranked queues in the settings.
+func validateQueue(queue string) (valid bool, code types.B2ResultCode, info string) {
+	if _, ok := settings.Queues[queue]; !ok && queue != settings.QueueRanked {
+		return false, types.QueueInvalid, fmt.Sprintf("Queue [ %v ] not recognized", queue)
+	}
+
+	return true, code, info
+}
+
+// getMatchSubmission returns the submission for an MMR update request, identified by the Idempotency-Key header if it
+// is present, and by the match ID if it is present - or nil if the request identifies neither, as it can't be
+// deduplicated. The match ID is always used when present, so that the same match can't be rated twice by submitting it
+// under a different key. If the header is invalid, a response describing the problem is returned, and ok is false.
+func getMatchSubmission(headers map[string]string, mmrur types.MMRUpdateRequest) (submission *types.MatchSubmission, ok bool, r types.LambdaResponse) {
+	var keys []string
+	if value, found := headers[idempotencyKeyHeader]; found {
+		if value == "" || len(value) > settings.IdempotencyKeyMaxLength {
+			return nil, false, packageGenericError(400, types.IdempotencyKeyInvalid, fmt.Errorf("%v header must be between 1 and %v bytes", idempotencyKeyHeader, settings.IdempotencyKeyMaxLength))
+		}
+
+		keys = append(keys, "key:"+value)
+	}
+
+	if mmrur.MatchID != nil {
+		keys = append(keys, fmt.Sprintf("match:%v", *mmrur.MatchID))
+	}
+
+	if len(keys) == 0 {
+		return nil, true, r
+	}
+
+	// Hash the request as it was parsed, rather than the raw body, so that retries that only differ in formatting are
+	// treated as the same payload.
+	payload, err := json.Marshal(mmrur)
+	if err != nil {
+		return nil, false, packageGenericError(400, types.RequestMarshalError, err)
+	}
+
+	hash := sha256.Sum256(payload)
+
+	return &types.MatchSubmission{Keys: keys, PayloadHash: hex.EncodeToString(hash[:])}, true, r
+}
+
+// replayMatchSubmission returns the response for a match result submission that has already been processed under any
+// of its keys - the original response if the payload is the same, or a conflict if it is different. If the submission
+// has not been processed, ok is false.
+func replayMatchSubmission(submission types.MatchSubmission) (r types.LambdaResponse, ok bool) {
+	var processed *types.MatchSubmission
+	for _, key := range submission.Keys {
+		var err error
+		processed, err = database.GetMatchSubmission(key)
+		if err != nil {
+			return packageGenericError(500, types.DatabaseError, err), true
+		}
+
+		if processed != nil {
+			break
+		}
+	}
+
+	if processed == nil {
+		return r, false
+	}
+
+	if processed.PayloadHash != submission.PayloadHash {
+		return packageGenericError(409, types.MatchSubmissionConflict, errors.New("Match already submitted with a different result")), true
+	}
+
+	return types.MakeLambdaResponse(200, types.Success, json.RawMessage(processed.Response)), true
+}
+
+// getArchivedSeason returns the ID of the season specified by a query param value, if the season exists and its final
+// standings have been archived. Otherwise, a response describing the problem is returned, and ok is false.
+func getArchivedSeason(value string) (seasonID uint64, ok bool, r types.LambdaResponse) {
+	seasonID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return seasonID, false, packageGenericError(400, types.SeasonIDInvalid, errors.New("'season' query param invalid"))
+	}
+
+	season, err := database.GetSeason(seasonID)
+	if err != nil {
+		if err.Error() == "Season not found" {
+			return seasonID, false, packageGenericError(404, types.SeasonNotFound, err)
+		}
+
+		return seasonID, false, packageGenericError(500, types.DatabaseError, err)
+	}
+
+	if season.RolledOver == nil {
+		return seasonID, false, packageGenericError(404, types.SeasonStandingsUnavailable, errors.New("Season has not been rolled over yet"))
+	}
+
+	return seasonID, true, r
+}
+
+// validateReservedHandle returns true if the specified handle and match type can be added to the reserved handles store.
+func validateReservedHandle(handle string, match types.ReservedHandleMatch) (valid bool, code types.B2ResultCode, info string) {
+
+	// Check that the match type is one of the known types.
+	if match > types.ReservedHandlePattern {
+		code = types.ReservedHandleMatchInvalid
+		info = "Match must be an int between 0 and 2 inclusive"
+		return false, code, info
+	}
+
+	// Exact and prefix entries cannot be empty, as an empty prefix would match every handle.
+	if match != types.ReservedHandlePattern && len(strings.TrimSpace(handle)) == 0 {
+		code = types.ReservedHandleHandleMissingOrWrongType
+		info = "Reserved handles cannot be empty"
+		return false, code, info
+	}
+
+	// Patterns must be valid regular expressions.
+	if match == types.ReservedHandlePattern {
+		if _, err := regexp.Compile(handle); err != nil {
+			code = types.ReservedHandlePatternInvalid
+			info = fmt.Sprintf("Pattern is not a valid regular expression: %v", err.Error())
+			return false, code, info
+		}
+	}
+
+	return true, code, info
+}
+
 // validateEmailFormat returns true if the specified email address meets the requirements for this application.
 func validateEmailFormat(email string) (valid bool, code types.B2ResultCode, info string) {
 
@@ -240,6 +703,16 @@ func validateEmailFormat(email string) (valid bool, code types.B2ResultCode, inf
 	return valid, code, info
 }
 
+// validateEmailNotDisposable returns true if the specified email address is not from a disposable email domain. Blocked
+// should be true if the domain was found in the blocked email domains table, which supplements the embedded list.
+func validateEmailNotDisposable(email string, blocked bool) (valid bool, code types.B2ResultCode, info string) {
+	if blocked || validation.DisposableEmailDomain(validation.EmailDomain(email)) {
+		return false, types.EmailDisposable, "Disposable email addresses are not allowed"
+	}
+
+	return true, code, info
+}
+
 // packageCreateAccountError creates a lamda response based on the specified account creation error.
 func packageCreateAccountError(err error) (response types.LambdaResponse) {
 
@@ -253,7 +726,10 @@ func packageCreateAccountError(err error) (response types.LambdaResponse) {
 		if strings.Contains(err.Error(), "email_UNIQUE") {
 			code = types.EmailAlreadyInUse
 			payload = "Email address already in use"
-		} else if strings.Contains(err.Error(), "handle_UNIQUE") {
+		} else if strings.Contains(err.Error(), "email_canonical_UNIQUE") {
+			code = types.EmailEquivalentInUse
+			payload = "An equivalent email address is already in use"
+		} else if strings.Contains(err.Error(), "handle_UNIQUE") || strings.Contains(err.Error(), "handle_key_UNIQUE") {
 			code = types.HandleAlreadyInUse
 			payload = "Handle already in use"
 		}
@@ -351,3 +827,189 @@ func packageAuthTokenCheckError(publicID string, err error) (response types.Lamb
 	// Package and return the code and message payload as a lambda response.
 	return types.MakeLambdaResponse(htmlCode, code, payload)
 }
+
+// packagePrivilegeUpdateError creates a lamda response based on the specified privilege update error.
+func packagePrivilegeUpdateError(err error) (response types.LambdaResponse) {
+
+	// Declare variables for the code and payload, to be set depending on the error.
+	code := types.DatabaseError
+	htmlCode := types.HTTPCode(500)
+	payload := ""
+
+	// Depending on the contents of the error, determine the code and message body.
+	// Unexpected errors are packaged with a generic message.
+	if strings.Contains(err.Error(), "last remaining server admin") {
+		code = types.PrivilegeUpdateLastServerAdmin
+		htmlCode = types.HTTPCode(409)
+		payload = "Cannot demote the last remaining server admin"
+	} else {
+		payload = fmt.Sprintf("Unknown database error: %v", err.Error())
+	}
+
+	// Package and return the code and message payload as a lambda response.
+	return types.MakeLambdaResponse(htmlCode, code, payload)
+}
+
+// kPolicy decides the elo k value for each player, based on the settings.
+var kPolicy = elo.KPolicy{
+	PlacementGames: settings.PlacementGames,
+	ProvisionalK:   settings.KFactorProvisional,
+	K:              settings.KFactorEstablished,
+	Bands: []elo.KBand{
+		{MinRating: settings.KFactorHighRatingThreshold, K: settings.KFactorHighRating},
+	},
+}
+
+// tierPolicy places players into tiers and divisions, based on the settings.
+var tierPolicy = elo.TierPolicy{
+	Tiers:           settings.Tiers,
+	PromotionBuffer: settings.TierPromotionBuffer,
+	DemotionBuffer:  settings.TierDemotionBuffer,
+}
+
+// repeatPolicy reduces rating gains from repeated matches between the same players, based on the settings.
+var repeatPolicy = elo.RepeatPolicy{
+	FreeMatches: settings.RepeatFreeMatches,
+	Factor:      settings.RepeatGainFactor,
+}
+
+// newRater returns the rater for the rating system with the specified name - either "elo" or "glicko2". If no name is
+// specified, elo is used, as it was the only rating system before others were added.
+func newRater(name string) (rater elo.Rater, err error) {
+	switch name {
+	case "", "elo":
+		return elo.NewCalculator(elo.WithKPolicy(kPolicy)), nil
+	case "glicko2":
+		return elo.Glicko2{Tau: settings.GlickoTau}, nil
+	}
+
+	return rater, fmt.Errorf("Rating system [ %v ] not recognized", name)
+}
+
+// newQueueRater returns the rater for matches in the unranked queue with the specified name, which must exist.
+func newQueueRater(queue string) elo.Rater {
+	policy := settings.Queues[queue]
+
+	return elo.NewCalculator(elo.WithKPolicy(policy))
+}
+
+// matchStatsRating returns the rating stored in the specified match stats.
+func matchStatsRating(matchStats types.MatchStats) elo.Rating {
+	return elo.Rating{
+		Value:      float64(matchStats.MMR),
+		Deviation:  matchStats.Deviation,
+		Volatility: matchStats.Volatility,
+		Games:      matchStats.Wins + matchStats.Draws + matchStats.Losses,
+	}
+}
+
+// setMatchStatsRating stores the specified rating in the match stats. The rating is rounded to the nearest whole point,
+// as that is how MMR is stored - the error this introduces is far smaller than the deviation of any rating.
+func setMatchStatsRating(matchStats *types.MatchStats, rating elo.Rating) {
+	matchStats.MMR = int16(math.Round(rating.Value))
+	matchStats.Deviation = rating.Deviation
+	matchStats.Volatility = rating.Volatility
+}
+
+// matchStatsPlacement returns the tier and division stored in the match stats. If the player has not been placed, was
+// placed in a tier that no longer exists, or their MMR has since moved beyond the buffers (such as after a season was
+// rolled over), they are placed from their MMR instead.
+func matchStatsPlacement(matchStats types.MatchStats) elo.Placement {
+	return tierPolicy.Update(elo.Placement{Tier: matchStats.Tier, Division: matchStats.Division}, float64(matchStats.MMR))
+}
+
+// setMatchStatsPlacement moves the player from the specified placement into a new tier and division if the MMR stored
+// in the match stats warrants it, applying the buffers, and stores the result in the match stats.
+func setMatchStatsPlacement(matchStats *types.MatchStats, previous elo.Placement) {
+	placement := tierPolicy.Update(previous, float64(matchStats.MMR))
+	matchStats.Tier = placement.Tier
+	matchStats.Division = placement.Division
+}
+
+// addPairingMatch returns the stats for the recent matches between a pair of players, with a match that has the
+// specified winner and duration (if known) added.
+func addPairingMatch(stats types.PairingStats, winner elo.Player, duration *uint32) types.PairingStats {
+	stats.Matches++
+	if winner == elo.Player1 {
+		stats.Player1Wins++
+	} else if winner == elo.Player2 {
+		stats.Player2Wins++
+	} else {
+		stats.Draws++
+	}
+
+	if duration != nil && *duration < settings.BoostingShortMatch {
+		stats.Short++
+	}
+
+	return stats
+}
+
+// detectBoosting returns review flags for any patterns in the recent matches between a pair of players that suggest
+// rating farming - one player losing most of their matches against the other, or many very short matches.
+func detectBoosting(player1DatabaseID uint64, player2DatabaseID uint64, stats types.PairingStats) (flags []types.ReviewFlag) {
+	if stats.Matches >= settings.BoostingFlagMinMatches {
+		losses := map[uint64]uint32{player1DatabaseID: stats.Player2Wins, player2DatabaseID: stats.Player1Wins}
+		opponents := map[uint64]uint64{player1DatabaseID: player2DatabaseID, player2DatabaseID: player1DatabaseID}
+
+		for _, player := range []uint64{player1DatabaseID, player2DatabaseID} {
+			if float64(losses[player])/float64(stats.Matches) >= settings.BoostingFlagLossRate {
+				flags = append(flags, types.ReviewFlag{
+					Player:   player,
+					Opponent: opponents[player],
+					Reason:   types.ReviewRepeatedLosses,
+					Details:  fmt.Sprintf("Lost %v of %v matches against the opponent in the last %v hours", losses[player], stats.Matches, settings.RepeatWindow),
+				})
+			}
+		}
+	}
+
+	if stats.Short >= settings.BoostingFlagShortMatches {
+
+		// Short matches are flagged for the pair, so the lower database ID is always used as the player, to avoid adding
+		// the same flag twice.
+		player, opponent := player1DatabaseID, player2DatabaseID
+		if opponent < player {
+			player, opponent = opponent, player
+		}
+
+		flags = append(flags, types.ReviewFlag{
+			Player:   player,
+			Opponent: opponent,
+			Reason:   types.ReviewShortMatches,
+			Details:  fmt.Sprintf("%v of %v matches against the opponent in the last %v hours were shorter than %v seconds", stats.Short, stats.Matches, settings.RepeatWindow, settings.BoostingShortMatch),
+		})
+	}
+
+	return flags
+}
+
+// makeExpectedChange returns the change in MMR for both players, if a match between them had the specified outcome.
+func makeExpectedChange(rater elo.Rater, player1MatchStats types.MatchStats, player2MatchStats types.MatchStats, winner elo.Player) types.ExpectedChange {
+	player1Rating, player2Rating := rater.Rate(matchStatsRating(player1MatchStats), matchStatsRating(player2MatchStats), winner)
+
+	player1After, player2After := player1MatchStats, player2MatchStats
+	setMatchStatsRating(&player1After, player1Rating)
+	setMatchStatsRating(&player2After, player2Rating)
+
+	return types.ExpectedChange{
+		Player1: player1After.MMR - player1MatchStats.MMR,
+		Player2: player2After.MMR - player2MatchStats.MMR,
+	}
+}
+
+// makeMMRChange returns the change in a player's MMR and placement, from their MMR and placement before a match, and
+// their match stats after it.
+func makeMMRChange(before int16, previous elo.Placement, after types.MatchStats) types.MMRChange {
+	comparison := tierPolicy.Compare(elo.Placement{Tier: after.Tier, Division: after.Division}, previous)
+
+	return types.MMRChange{
+		Before:   before,
+		After:    after.MMR,
+		Delta:    after.MMR - before,
+		Tier:     after.Tier,
+		Division: after.Division,
+		Promoted: comparison > 0,
+		Demoted:  comparison < 0,
+	}
+}
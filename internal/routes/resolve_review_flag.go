@@ -0,0 +1,87 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const reviewFlagIDParameterKey = "id"
+
+// ResolveReviewFlag removes the flag specified by the ID in the path /reviews/{id} from the moderation review queue,
+// once a moderator has reviewed it. Any action against the flagged accounts is taken separately.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func ResolveReviewFlag(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Extract the username and password from the Authorization header.
+	handle, password, err := auth.ExtractCredentials(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the account specified user has the required privilege level to perform this action.
+	// Note that this is done before the credentials check, as the credentials check is fairly slow and being able to
+	// exit early should reduce server load.
+	hasRequiredPrivilege, err := database.HasRequiredPrivilege(handle, database.ServerAdminPrivilege)
+	if err != nil || !hasRequiredPrivilege {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check to see if the parsed username and password are valid.
+	err = database.ValidateCredentials(handle, password)
+	if err != nil {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check for the existence of, and then parse the value for the "id" path parameter.
+	id, ok := request.PathParameters[reviewFlagIDParameterKey]
+	if !ok {
+		r = packageGenericError(400, types.ReviewFlagIDMissing, errors.New("Review flag ID parameter missing"))
+		return r, nil
+	}
+
+	flagID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		r = packageGenericError(400, types.ReviewFlagIDInvalid, errors.New("Review flag ID parameter invalid"))
+		return r, nil
+	}
+
+	// Get the database ID for the caller, so that the resolution can be attributed to them.
+	actorDatabaseID, _, err := database.GetIDs(handle)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Attempt to resolve the flag.
+	err = database.ResolveReviewFlag(flagID, uint64(actorDatabaseID))
+	if err != nil {
+		if err.Error() == "Review flag not found" {
+			r = packageGenericError(404, types.ReviewFlagNotFound, err)
+		} else {
+			r = packageGenericError(500, types.DatabaseError, err)
+		}
+
+		return r, nil
+	}
+
+	// Package an empty string in a lambda response - note the status code of 204, a success with no message body.
+	r = types.MakeLambdaResponse(204, types.Success, "")
+
+	return r, nil
+}
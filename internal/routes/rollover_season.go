@@ -0,0 +1,89 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+const seasonIDParameterKey = "id"
+
+// RolloverSeason ends the season specified by the ID in the path /seasons/{id}/rollover. The final standings are
+// archived, and every player's mmr is soft reset toward the default mmr. The season must have ended, and can only be
+// rolled over once.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func RolloverSeason(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Extract the username and password from the Authorization header.
+	handle, password, err := auth.ExtractCredentials(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the account specified user has the required privilege level to perform this action.
+	// Note that this is done before the credentials check, as the credentials check is fairly slow and being able to
+	// exit early should reduce server load.
+	hasRequiredPrivilege, err := database.HasRequiredPrivilege(handle, database.ServerAdminPrivilege)
+	if err != nil || !hasRequiredPrivilege {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check to see if the parsed username and password are valid.
+	err = database.ValidateCredentials(handle, password)
+	if err != nil {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check for the existence of, and then parse the value for the "id" path parameter.
+	id, ok := request.PathParameters[seasonIDParameterKey]
+	if !ok {
+		r = packageGenericError(400, types.SeasonIDMissing, errors.New("Season ID parameter missing"))
+		return r, nil
+	}
+
+	seasonID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		r = packageGenericError(400, types.SeasonIDInvalid, errors.New("Season ID parameter invalid"))
+		return r, nil
+	}
+
+	// Attempt to roll over the season, keeping the fraction of each player's distance from the default mmr that is
+	// not removed by the soft reset.
+	err = database.RolloverSeason(seasonID, int16(elo.Default), 1-settings.SeasonSoftReset)
+	if err != nil {
+		switch err.Error() {
+		case "Season not found":
+			r = packageGenericError(404, types.SeasonNotFound, err)
+		case "Season has not ended":
+			r = packageGenericError(409, types.SeasonNotEnded, err)
+		case "Season has already been rolled over":
+			r = packageGenericError(409, types.SeasonAlreadyRolledOver, err)
+		default:
+			r = packageGenericError(500, types.DatabaseError, err)
+		}
+
+		return r, nil
+	}
+
+	// Package an empty string in a lambda response - note the status code of 204, a success with no message body.
+	r = types.MakeLambdaResponse(204, types.Success, "")
+
+	return r, nil
+}
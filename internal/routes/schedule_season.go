@@ -0,0 +1,96 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// ScheduleSeason schedules a ranked season, using the details specified in the message body
+// { name: {String}, start: {String}, end: {String} }, where start and end are RFC 3339 timestamps. Seasons may not
+// overlap, and must end in the future.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func ScheduleSeason(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Extract the username and password from the Authorization header.
+	handle, password, err := auth.ExtractCredentials(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the account specified user has the required privilege level to perform this action.
+	// Note that this is done before the credentials check, as the credentials check is fairly slow and being able to
+	// exit early should reduce server load.
+	hasRequiredPrivilege, err := database.HasRequiredPrivilege(handle, database.ServerAdminPrivilege)
+	if err != nil || !hasRequiredPrivilege {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check to see if the parsed username and password are valid.
+	err = database.ValidateCredentials(handle, password)
+	if err != nil {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Attempt to parse the request body as a SeasonRequest struct.
+	sr := types.SeasonRequest{}
+	err = json.Unmarshal([]byte(request.Body), &sr)
+	if err != nil {
+		r = packageGenericError(400, types.RequestMarshalError, err)
+		return r, nil
+	}
+
+	// Check to ensure that all the expected fields were present in the JSON
+	// body, with the correct format, type etc..
+	fieldsValid, code, info := validateSRFields(sr)
+	if !fieldsValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Check that the name and dates are valid for a new season.
+	seasonValid, code, info := validateSeason(*sr.Name, *sr.Start, *sr.End)
+	if !seasonValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Get the database ID for the caller, so that the season can be attributed to them.
+	actorDatabaseID, _, err := database.GetIDs(handle)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Attempt to add the season.
+	id, err := database.AddSeason(*sr.Name, *sr.Start, *sr.End, uint64(actorDatabaseID))
+	if err != nil {
+		if err.Error() == "Season overlaps another season" {
+			r = packageGenericError(409, types.SeasonOverlaps, err)
+		} else {
+			r = packageGenericError(500, types.DatabaseError, err)
+		}
+
+		return r, nil
+	}
+
+	// Package the ID of the new season in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, types.SeasonResponsePayload{ID: id})
+
+	return r, nil
+}
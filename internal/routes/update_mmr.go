@@ -9,15 +9,32 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"os"
 
 	"github.com/6a/blade-ii-api/internal/auth"
 	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
 	"github.com/6a/blade-ii-api/internal/types"
-	"github.com/6a/blade-ii-api/pkg/elo"
 	"github.com/aws/aws-lambda-go/events"
 )
 
-// UpdateMMR updates the mmr for the two specified clients, based on their current MMR, and which client won.
+// ratingSystem is the name of the rating system used for ranked matches, read from the environment variables. See
+// newRater for the supported rating systems.
+var ratingSystem = os.Getenv("rating_system")
+
+// idempotencyKeyHeader is the header that identifies a match result submission, for deduplicating retries.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// UpdateMMR updates the mmr for the two specified clients, based on their current MMR, and which client won, and
+// returns the MMR before and after the match for both clients, along with their tier and division, and whether they
+// were promoted or demoted. Matches involving an account that has not confirmed its email address are rejected, unless
+// the settings allow it. Rating gains are reduced if the players have played each other repeatedly, and suspicious
+// patterns of matches between the players are flagged for review. Matches in an unranked queue are rated in the
+// ladder for that queue instead, and none of the above apply to them, other than reporting the change in MMR.
+//
+// If the submission is identified by an Idempotency-Key header or a match ID, it is only processed once - a retry
+// with the same payload returns the original response, and a retry with a different payload is rejected.
 //
 // Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
 // code (RFC 7231).
@@ -61,6 +78,63 @@ func UpdateMMR(ctx context.Context, request events.APIGatewayProxyRequest) (r ty
 		return r, nil
 	}
 
+	// Check that the queue exists, if one was specified, and rate the match in its own ladder if it is not the ranked
+	// queue.
+	queue := settings.QueueRanked
+	if mmrur.Queue != nil {
+		queue = *mmrur.Queue
+	}
+
+	if valid, code, info := validateQueue(queue); !valid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Get the submission, so that a retry of a submission that has already been processed returns the original
+	// response, rather than rating the match again. The queue is filled in first, so that leaving it out and
+	// specifying the ranked queue are the same payload.
+	mmrur.Queue = &queue
+	submission, ok, r := getMatchSubmission(request.Headers, mmrur)
+	if !ok {
+		return r, nil
+	}
+
+	if submission != nil {
+		if r, ok := replayMatchSubmission(*submission); ok {
+			return r, nil
+		}
+	}
+
+	if queue != settings.QueueRanked {
+		r = updateQueueMMR(queue, mmrur, submission)
+		return r, nil
+	}
+
+	// Get the rater for the configured rating system. This is checked before anything else about the match, as no
+	// matches can be rated if it is misconfigured.
+	rater, err := newRater(ratingSystem)
+	if err != nil {
+		r = packageGenericError(500, types.RatingSystemInvalid, err)
+		return r, nil
+	}
+
+	// Unless the settings allow unconfirmed accounts to play ranked matches, check that both players have confirmed
+	// their email address, so that the match is not recorded for either player.
+	if !settings.UnconfirmedAccountsRanked {
+		for _, playerID := range []uint64{*mmrur.Player1ID, *mmrur.Player2ID} {
+			confirmed, err := database.EmailConfirmed(playerID)
+			if err != nil {
+				r = packageMMRUpdateError(playerID, err)
+				return r, nil
+			}
+
+			if !confirmed {
+				r = packageGenericError(403, types.PlayerEmailUnconfirmed, fmt.Errorf("MMR update error - player [ %v ] has not confirmed their email address", playerID))
+				return r, nil
+			}
+		}
+	}
+
 	// Get the match stats for the first player specified in the update request.
 	player1MatchStats, err := database.GetMatchStats(*mmrur.Player1ID)
 	if err != nil {
@@ -75,18 +149,140 @@ func UpdateMMR(ctx context.Context, request events.APIGatewayProxyRequest) (r ty
 		return r, nil
 	}
 
-	// Calculate the new MMR for both players.
-	player1MatchStats.MMR, player2MatchStats.MMR = elo.CalculateNewElo(player1MatchStats.MMR, player2MatchStats.MMR, *mmrur.Winner)
+	// Keep each player's MMR and placement before the match, to report the changes.
+	player1Before, player2Before := player1MatchStats.MMR, player2MatchStats.MMR
+	player1Placement, player2Placement := matchStatsPlacement(player1MatchStats), matchStatsPlacement(player2MatchStats)
+
+	// Get the stats for the recent matches between the players, so that rating gains from repeated matches can be
+	// reduced, and rating farming can be detected.
+	pairingStats, err := database.GetPairingStats(*mmrur.Player1ID, *mmrur.Player2ID)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Calculate the new rating for both players, using the configured rating system, and then reduce any gains if the
+	// players have played each other repeatedly.
+	recent := int(pairingStats.Matches)
+	player1Rating, player2Rating := rater.Rate(matchStatsRating(player1MatchStats), matchStatsRating(player2MatchStats), *mmrur.Winner)
+	player1Rating = repeatPolicy.Apply(matchStatsRating(player1MatchStats), player1Rating, recent)
+	player2Rating = repeatPolicy.Apply(matchStatsRating(player2MatchStats), player2Rating, recent)
+	setMatchStatsRating(&player1MatchStats, player1Rating)
+	setMatchStatsRating(&player2MatchStats, player2Rating)
 
-	// Update the match stats for both players.
-	err = database.UpdateMatchStats(*mmrur.Player1ID, player1MatchStats, *mmrur.Player2ID, player2MatchStats, *mmrur.Winner)
+	// Promote or demote both players if their new MMR warrants it.
+	setMatchStatsPlacement(&player1MatchStats, player1Placement)
+	setMatchStatsPlacement(&player2MatchStats, player2Placement)
+
+	// Record the pairing, flagging the players for review if their recent matches against each other (including this
+	// one) look like rating farming.
+	pairing := types.Pairing{
+		Duration:   mmrur.Duration,
+		Multiplier: repeatPolicy.Multiplier(recent),
+		Flags:      detectBoosting(*mmrur.Player1ID, *mmrur.Player2ID, addPairingMatch(pairingStats, *mmrur.Winner, mmrur.Duration)),
+	}
+
+	// Create a message body containing the return data for this API call - in this case the MMR before and after the
+	// match for both players, the change for each, and any promotion or demotion.
+	mmrUpdateResponse := types.MMRUpdateResponsePayload{
+		Queue:          queue,
+		Player1:        makeMMRChange(player1Before, player1Placement, player1MatchStats),
+		Player2:        makeMMRChange(player2Before, player2Placement, player2MatchStats),
+		GainMultiplier: pairing.Multiplier,
+	}
+
+	// Keep the response with the submission, if there is one, so that retries of the submission can return it.
+	if submission != nil {
+		submission.Response, err = json.Marshal(mmrUpdateResponse)
+		if err != nil {
+			r = packageGenericError(500, types.ResponseMarshalError, err)
+			return r, nil
+		}
+	}
+
+	// Update the match stats for both players, and record the submission as processed. If a concurrent retry of the
+	// submission was processed first, nothing is updated, and its response is returned instead.
+	err = database.UpdateMatchStats(mmrur.MatchID, *mmrur.Player1ID, player1MatchStats, *mmrur.Player2ID, player2MatchStats, *mmrur.Winner, pairing, submission)
 	if err != nil {
+		if err.Error() == "Match not found" {
+			r = packageGenericError(404, types.MatchNotFound, fmt.Errorf("MMR update error - match [ %v ] between the specified players not found", *mmrur.MatchID))
+			return r, nil
+		}
+
+		if err.Error() == "Match already submitted" {
+			if r, ok := replayMatchSubmission(*submission); ok {
+				return r, nil
+			}
+		}
+
 		r = packageGenericError(500, types.DatabaseError, err)
 		return r, nil
 	}
 
-	// Package an empty string in a lambda response - note the status code of 204, a success with no message body.
-	r = types.MakeLambdaResponse(204, types.Success, "")
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, mmrUpdateResponse)
 
 	return r, nil
 }
+
+// updateQueueMMR rates a match in the ladder for the specified unranked queue, and returns a response with the MMR
+// before and after the match for both clients. Unranked queues have no tiers, so the tier and division are left empty,
+// and rating gains are never reduced. If a submission is specified, it is recorded as processed along with the match.
+func updateQueueMMR(queue string, mmrur types.MMRUpdateRequest, submission *types.MatchSubmission) (r types.LambdaResponse) {
+
+	// Get the match stats in the queue for the first player specified in the update request.
+	player1MatchStats, err := database.GetQueueMatchStats(queue, *mmrur.Player1ID)
+	if err != nil {
+		return packageMMRUpdateError(*mmrur.Player1ID, err)
+	}
+
+	// Get the match stats in the queue for the second player specified in the update request.
+	player2MatchStats, err := database.GetQueueMatchStats(queue, *mmrur.Player2ID)
+	if err != nil {
+		return packageMMRUpdateError(*mmrur.Player2ID, err)
+	}
+
+	// Keep each player's MMR before the match, to report the changes.
+	player1Before, player2Before := player1MatchStats.MMR, player2MatchStats.MMR
+
+	// Calculate the new rating for both players, using the rating parameters for the queue.
+	player1Rating, player2Rating := newQueueRater(queue).Rate(matchStatsRating(player1MatchStats), matchStatsRating(player2MatchStats), *mmrur.Winner)
+	setMatchStatsRating(&player1MatchStats, player1Rating)
+	setMatchStatsRating(&player2MatchStats, player2Rating)
+
+	// Create a message body containing the MMR before and after the match for both players, and the change for each.
+	mmrUpdateResponse := types.MMRUpdateResponsePayload{
+		Queue:          queue,
+		Player1:        types.MMRChange{Before: player1Before, After: player1MatchStats.MMR, Delta: player1MatchStats.MMR - player1Before},
+		Player2:        types.MMRChange{Before: player2Before, After: player2MatchStats.MMR, Delta: player2MatchStats.MMR - player2Before},
+		GainMultiplier: 1,
+	}
+
+	// Keep the response with the submission, if there is one, so that retries of the submission can return it.
+	if submission != nil {
+		submission.Response, err = json.Marshal(mmrUpdateResponse)
+		if err != nil {
+			return packageGenericError(500, types.ResponseMarshalError, err)
+		}
+	}
+
+	// Update the match stats in the queue for both players, and record the submission as processed. If a concurrent
+	// retry of the submission was processed first, nothing is updated, and its response is returned instead.
+	err = database.UpdateQueueMatchStats(queue, mmrur.MatchID, *mmrur.Player1ID, player1MatchStats, *mmrur.Player2ID, player2MatchStats, *mmrur.Winner, submission)
+	if err != nil {
+		if err.Error() == "Match not found" {
+			return packageGenericError(404, types.MatchNotFound, fmt.Errorf("MMR update error - match [ %v ] between the specified players not found", *mmrur.MatchID))
+		}
+
+		if err.Error() == "Match already submitted" {
+			if r, ok := replayMatchSubmission(*submission); ok {
+				return r
+			}
+		}
+
+		return packageGenericError(500, types.DatabaseError, err)
+	}
+
+	// Package the return payload in a lambda response.
+	return types.MakeLambdaResponse(200, types.Success, mmrUpdateResponse)
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package routes implements various endpoints for the Blade II REST API.
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/6a/blade-ii-api/internal/auth"
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/aws/aws-lambda-go/events"
+)
+
+// UpdatePrivilege sets the privilege level for the user specified by the public ID in the path /admin/privileges/{publicID},
+// with the privilege level specified in the message body { privilege: {Number}, passwordconfirmation: {String} }. Only
+// server admins may use this route, and raising the privilege level of an account requires the caller to re-confirm their
+// password in the message body.
+//
+// Errors will never be returned, and instead will be handled by returning a response with a suitable HTTP status
+// code (RFC 7231).
+func UpdatePrivilege(ctx context.Context, request events.APIGatewayProxyRequest) (r types.LambdaResponse, err error) {
+
+	// Extract the username and password from the Authorization header.
+	handle, password, err := auth.ExtractCredentials(request.Headers)
+	if err != nil {
+		r = packageGenericError(401, types.AuthHeaderMissing, err)
+		return r, nil
+	}
+
+	// Check to see if the account specified user has the required privilege level to perform this action.
+	// Note that this is done before the credentials check, as the credentials check is fairly slow and being able to
+	// exit early should reduce server load.
+	hasRequiredPrivilege, err := database.HasRequiredPrivilege(handle, database.ServerAdminPrivilege)
+	if err != nil || !hasRequiredPrivilege {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check to see if the parsed username and password are valid.
+	err = database.ValidateCredentials(handle, password)
+	if err != nil {
+		r = packageGenericError(403, types.AuthUsernameOrPasswordIncorrect, errors.New("Username or password is incorrect"))
+		return r, nil
+	}
+
+	// Check for the existence of, and then get the value for the "pid" path parameter.
+	var pid string
+	if _, ok := request.PathParameters[publicIDParameterKey]; ok {
+		pid = request.PathParameters[publicIDParameterKey]
+	} else {
+		r = packageGenericError(400, types.PrivilegeUpdatePublicIDMissing, errors.New("Public ID parameter missing"))
+		return r, nil
+	}
+
+	// Attempt to parse the request body as a PrivilegeUpdateRequest struct.
+	pur := types.PrivilegeUpdateRequest{}
+	err = json.Unmarshal([]byte(request.Body), &pur)
+	if err != nil {
+		r = packageGenericError(400, types.RequestMarshalError, err)
+		return r, nil
+	}
+
+	// Check to ensure that all the expected fields were present in the JSON
+	// body, with the correct format, type etc..
+	fieldsValid, code, info := validatePURFields(pur)
+	if !fieldsValid {
+		r = types.MakeLambdaResponse(400, code, info)
+		return r, nil
+	}
+
+	// Check that the privilege level is one of the known levels.
+	if !database.ValidPrivilege(*pur.Privilege) {
+		r = packageGenericError(400, types.PrivilegeUpdatePrivilegeValueInvalid, errors.New("Privilege must be an int between 0 and 2 inclusive"))
+		return r, nil
+	}
+
+	// Attempt to get the database ID for the user specified by public ID.
+	targetDatabaseID, err := database.GetDatabaseID(pid)
+	if err != nil {
+		r = packageGenericError(404, types.PrivilegeUpdatePublicIDNotFound, errors.New("Public ID not found"))
+		return r, nil
+	}
+
+	// Get the database ID for the caller, so that the change can be attributed to them in the audit trail.
+	actorDatabaseID, _, err := database.GetIDs(handle)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Get the current privilege level for the target user, to determine whether or not this is an escalation.
+	currentPrivilege, err := database.GetPrivilege(targetDatabaseID)
+	if err != nil {
+		r = packageGenericError(500, types.DatabaseError, err)
+		return r, nil
+	}
+
+	// Escalations require the caller to re-confirm their password, so that a leaked or cached auth header alone
+	// is not enough to hand out admin rights.
+	if *pur.Privilege > currentPrivilege {
+		if pur.PasswordConfirmation == nil {
+			r = packageGenericError(400, types.PrivilegeUpdatePasswordConfirmationMissing, errors.New("Password confirmation is required to raise privileges"))
+			return r, nil
+		}
+
+		err = database.ValidateCredentials(handle, *pur.PasswordConfirmation)
+		if err != nil {
+			r = packageGenericError(403, types.PrivilegeUpdatePasswordConfirmationIncorrect, errors.New("Password confirmation is incorrect"))
+			return r, nil
+		}
+	}
+
+	// Attempt to update the privilege level for the target user. This also writes the change to the audit trail.
+	oldPrivilege, err := database.UpdatePrivilege(uint64(actorDatabaseID), targetDatabaseID, *pur.Privilege)
+	if err != nil {
+		r = packagePrivilegeUpdateError(err)
+		return r, nil
+	}
+
+	// Create a message body containing the return data for this API call - in this case the
+	// public ID for the target user, as well as their old and new privilege levels.
+	privilegeUpdateResponse := types.PrivilegeUpdateResponsePayload{
+		PublicID:     pid,
+		OldPrivilege: oldPrivilege,
+		NewPrivilege: *pur.Privilege,
+	}
+
+	// Package the return payload in a lambda response.
+	r = types.MakeLambdaResponse(200, types.Success, privilegeUpdateResponse)
+
+	return r, nil
+}
@@ -0,0 +1,20 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package settings is a utility package that contains various app-wide constants.
+package settings
+
+import "github.com/6a/blade-ii-api/pkg/elo"
+
+// QueueRanked is the name of the ranked queue, which matches are submitted to unless another queue is specified. Its
+// ladder is stored with each profile, and it is the only queue with seasons, tiers, decay, and rating farming checks.
+const QueueRanked = "ranked"
+
+// Queues are the unranked queues, by name, with the k policy used to rate the matches in each. Each queue has its own
+// ladder, separate from the ranked ladder and from each other. Matches in these queues are always rated with elo,
+// regardless of the rating system used for ranked matches.
+var Queues = map[string]elo.KPolicy{
+	"casual": {PlacementGames: PlacementGames, ProvisionalK: 32, K: 16},
+	"event":  {ProvisionalK: 40, K: 40},
+}
@@ -13,6 +13,93 @@ const (
 	// EmailConfirmationTokenLength is the length of a generated email confirmation token.
 	EmailConfirmationTokenLength = 32
 
+	// UnconfirmedAccountsRanked determines whether accounts that have not confirmed their email address can take part in
+	// ranked matches. If false, MMR updates for matches involving an unconfirmed account are rejected. Unconfirmed accounts
+	// can always log in and play casual matches.
+	UnconfirmedAccountsRanked = false
+
+	// UnconfirmedAccountsOnLeaderboards determines whether accounts that have not confirmed their email address are
+	// included in the leaderboards.
+	UnconfirmedAccountsOnLeaderboards = false
+
+	// PlacementGames is the number of ranked matches a player must play before their rating is no longer provisional.
+	PlacementGames = 10
+
+	// ProvisionalOnLeaderboards determines whether players whose rating is still provisional are included in the
+	// leaderboards. They are flagged as provisional either way.
+	ProvisionalOnLeaderboards = true
+
+	// KFactorProvisional is the elo k value (the maximum elo shift after a match) for players whose rating is still
+	// provisional, so that they reach their true rating quickly.
+	KFactorProvisional = 40
+
+	// KFactorEstablished is the elo k value for players whose rating is established.
+	KFactorEstablished = 32
+
+	// KFactorHighRating is the elo k value for established players rated at or above KFactorHighRatingThreshold, so that
+	// the top of the leaderboards is stable.
+	KFactorHighRating = 16
+
+	// KFactorHighRatingThreshold is the MMR at and above which established players use KFactorHighRating.
+	KFactorHighRatingThreshold = 2000
+
+	// TierPromotionBuffer is how far above the bottom of a higher division (see Tiers) a player's MMR must be before they
+	// are promoted into it, so that a player hovering at a boundary doesn't flicker between divisions.
+	TierPromotionBuffer = 10
+
+	// TierDemotionBuffer is how far below the bottom of their current division a player's MMR must be before they are
+	// demoted out of it.
+	TierDemotionBuffer = 20
+
+	// RepeatWindow is the number of hours for which matches between a pair of players count as recent, for reducing
+	// rating gains from repeated matches and detecting rating farming.
+	RepeatWindow = 24
+
+	// RepeatFreeMatches is the number of recent matches a pair of players can play against each other before rating
+	// gains from matches between them are reduced.
+	RepeatFreeMatches = 3
+
+	// RepeatGainFactor is the multiplier applied to rating gains for each recent match between a pair of players beyond
+	// RepeatFreeMatches.
+	RepeatGainFactor = 0.5
+
+	// BoostingFlagMinMatches is the number of recent matches a pair of players must have played against each other before
+	// one of them can be flagged for review for repeatedly losing to the other.
+	BoostingFlagMinMatches = 5
+
+	// BoostingFlagLossRate is the fraction of recent matches against the same opponent that a player must have lost to be
+	// flagged for review.
+	BoostingFlagLossRate = 0.8
+
+	// BoostingShortMatch is the length, in seconds, below which a match is considered suspiciously short.
+	BoostingShortMatch = 60
+
+	// BoostingFlagShortMatches is the number of recent short matches between a pair of players that will flag them for
+	// review.
+	BoostingFlagShortMatches = 3
+
+	// IdempotencyKeyMaxLength is the maximum length, in bytes, of the Idempotency-Key header for a match result
+	// submission.
+	IdempotencyKeyMaxLength = 64
+
+	// RatingHistoryMaxCount is the maximum number of rating history points that can be requested at once, either as a
+	// range or downsampled.
+	RatingHistoryMaxCount = 500
+
+	// SeasonSoftReset is the fraction of the difference between each player's MMR and the default MMR that is removed
+	// when a season is rolled over - 0 keeps every player's MMR, and 1 resets every player to the default.
+	SeasonSoftReset = 0.5
+
+	// SeasonNameMaxLength is the maximum length, in runes, of the name of a season.
+	SeasonNameMaxLength = 64
+
+	// MatchQualityMaxPlayers is the maximum number of players that can be compared in a single match quality request.
+	MatchQualityMaxPlayers = 8
+
+	// GlickoTau is the system constant for the Glicko-2 rating system, which constrains the change in volatility over
+	// time. Reasonable values are between 0.3 and 1.2.
+	GlickoTau = 0.5
+
 	// AuthTokenLifetime is the number of hours for which an auth token will be valid.
 	AuthTokenLifetime = 1
 
@@ -24,4 +111,41 @@ const (
 
 	// RefreshTokenLength is the length of a generated auth refresh token.
 	RefreshTokenLength = 32
+
+	// ProtectedLeaderboardSize is the number of top ranked players whose handles are protected from impersonation.
+	ProtectedLeaderboardSize = 100
+
+	// ProfanityDictionaryLifetime is the number of minutes for which a loaded profanity dictionary will be used before
+	// it is reloaded.
+	ProfanityDictionaryLifetime = 10
+
+	// ChatFilterMaxLines is the maximum number of lines of chat that can be filtered in a single request.
+	ChatFilterMaxLines = 100
+
+	// ChatFilterMaxLineLength is the maximum length, in runes, of a single line of chat that can be filtered.
+	ChatFilterMaxLineLength = 512
+
+	// AvailabilityRateLimit is the maximum number of availability checks that a single IP address can make per window.
+	AvailabilityRateLimit = 30
+
+	// AvailabilityRateLimitWindow is the number of seconds in each availability check rate limit window.
+	AvailabilityRateLimitWindow = 60
+
+	// SignupChallengeLifetime is the number of minutes for which a signup challenge will be valid.
+	SignupChallengeLifetime = 5
+
+	// SignupChallengeBaseDifficulty is the difficulty, in leading zero bits, of a signup challenge for an IP address
+	// that has not created any accounts recently.
+	SignupChallengeBaseDifficulty = 18
+
+	// SignupChallengeMaxDifficulty is the highest difficulty, in leading zero bits, of a signup challenge.
+	SignupChallengeMaxDifficulty = 26
+
+	// SignupChallengeStep is the number of accounts an IP address can create within the signup window before the
+	// difficulty of its signup challenges increases by one bit (doubling the work required).
+	SignupChallengeStep = 2
+
+	// SignupWindow is the number of seconds for which account creations from an IP address count towards its
+	// signup challenge difficulty.
+	SignupWindow = 3600
 )
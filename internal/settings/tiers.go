@@ -0,0 +1,35 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package settings is a utility package that contains various app-wide constants.
+package settings
+
+import (
+	"time"
+
+	"github.com/6a/blade-ii-api/pkg/elo"
+)
+
+// Tiers are the rank tiers that players are placed into based on their MMR, in ascending order of MMR. Each tier is
+// split evenly into divisions, and the highest tier always has a single division. Players keep their stored tier and
+// division if the tiers change, until their next ranked match.
+var Tiers = []elo.Tier{
+	{Name: "Bronze", MinRating: 800, Divisions: 4},
+	{Name: "Silver", MinRating: 1000, Divisions: 4},
+	{Name: "Gold", MinRating: 1200, Divisions: 4},
+	{Name: "Platinum", MinRating: 1400, Divisions: 4},
+	{Name: "Diamond", MinRating: 1600, Divisions: 4},
+	{Name: "Master", MinRating: 1800, Divisions: 1},
+}
+
+// Decay is how the MMR of players who have not played a ranked match recently decays, for each tier. Decay is applied
+// at most once a day, by the scheduled decay handler. Players in tiers without a rule never decay.
+var Decay = map[string]elo.DecayRule{
+	"Platinum": {GracePeriod: 28 * day, Deviation: 10},
+	"Diamond":  {GracePeriod: 21 * day, Points: 5, Floor: 1600, Deviation: 10},
+	"Master":   {GracePeriod: 14 * day, Points: 10, Floor: 1800, Deviation: 10},
+}
+
+// day is the length of a day, for the decay grace periods.
+const day = 24 * time.Hour
@@ -0,0 +1,51 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package main implements a one-off migration tool that reports existing accounts whose email addresses share the same
+// canonical form, and backfills the "email_canonical" column. Run without arguments for a dry run, or with -apply to
+// write the canonical emails.
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/6a/blade-ii-api/internal/database"
+)
+
+func main() {
+
+	// Parse the command line flags.
+	apply := flag.Bool("apply", false, "write the canonical emails to the database")
+	flag.Parse()
+
+	// Initialize the database package.
+	database.Init()
+
+	// Find any existing accounts whose email addresses share a canonical form.
+	_, collisions, err := database.GetCanonicalEmails()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Report each collision - only the first (oldest) account in each will be given the canonical email.
+	for _, collision := range collisions {
+		log.Printf("collision on %q: ids %v, emails [ %v ]", collision.Canonical, collision.DatabaseIDs, strings.Join(collision.Emails, ", "))
+	}
+
+	log.Printf("found %v canonical email collisions", len(collisions))
+
+	// Exit here unless the canonical emails should actually be written.
+	if !*apply {
+		return
+	}
+
+	updated, skipped, err := database.BackfillCanonicalEmails()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("backfilled canonical emails for %v users, skipped %v colliding users", updated, skipped)
+}
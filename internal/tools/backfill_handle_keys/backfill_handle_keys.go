@@ -0,0 +1,52 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package main implements a one-off migration tool that reports handle key collisions between existing accounts,
+// and backfills the "handle_key" column once there are none. Run without arguments for a dry run, or with -apply to
+// write the keys.
+package main
+
+import (
+	"flag"
+	"log"
+	"strings"
+
+	"github.com/6a/blade-ii-api/internal/database"
+)
+
+func main() {
+
+	// Parse the command line flags.
+	apply := flag.Bool("apply", false, "write the handle keys to the database, if there are no collisions")
+	flag.Parse()
+
+	// Initialize the database package.
+	database.Init()
+
+	// Find any existing accounts whose handles share a canonical key.
+	collisions, err := database.GetHandleKeyCollisions()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Report each collision, so that they can be resolved by hand.
+	for _, collision := range collisions {
+		log.Printf("collision on key %q: ids %v, handles [ %v ]", collision.Key, collision.DatabaseIDs, strings.Join(collision.Handles, ", "))
+	}
+
+	log.Printf("found %v handle key collisions", len(collisions))
+
+	// Exit here unless the keys should actually be written.
+	if !*apply {
+		return
+	}
+
+	// Attempt to backfill the keys - this will fail if any collisions were found above.
+	updated, err := database.BackfillHandleKeys()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("backfilled handle keys for %v users", updated)
+}
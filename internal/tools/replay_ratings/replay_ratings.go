@@ -0,0 +1,252 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package main implements an offline tool that replays every finished match, in chronological order, through a rating
+// system, for tuning the rating parameters with evidence. It reports how well the ratings predicted each result (log
+// loss, Brier score, and calibration), and the resulting leaderboard.
+//
+// Matches are read from the matches table, or from a JSONL export with -input, where each line is a match in the form
+// { player1: {Number}, player2: {Number}, winner: {Number} } (winner is 0 for a draw, or 1 or 2). Run with -diff to
+// compare the recomputed ratings with the current ratings, or with -apply to also write the recomputed ratings.
+//
+// The replay starts every player from the default rating, and only applies the rating system to each match. It does not
+// reproduce season soft resets, inactivity decay, reduced gains from repeated pairings, or the exclusion of accounts
+// that have not confirmed their email address. Matches rated in the unranked queues are skipped, but only if their
+// result was submitted with a match ID, so any other unranked matches are replayed as if they were ranked. Once any of
+// these have affected the live ratings, the recomputed ratings are not comparable with them, so -diff warns about it,
+// and -apply refuses to write them. -apply also can't be used with -input, as the matches in the file may not be the
+// matches that the live ratings came from.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/6a/blade-ii-api/internal/database"
+	"github.com/6a/blade-ii-api/internal/settings"
+	"github.com/6a/blade-ii-api/internal/types"
+	"github.com/6a/blade-ii-api/pkg/elo"
+)
+
+func main() {
+
+	// Parse the command line flags.
+	input := flag.String("input", "", "read matches from this JSONL file, instead of the matches table")
+	system := flag.String("system", "elo", "the rating system to replay the matches through - either \"elo\" or \"glicko2\"")
+	k := flag.Int("k", 0, "the elo k value for every player, instead of the k policy from the settings")
+	tenXMod := flag.Float64("tenxmod", elo.DefaultTenXMod, "the number of elo points required to be considered 10x better/worse than someone")
+	tau := flag.Float64("tau", settings.GlickoTau, "the Glicko-2 system constant")
+	buckets := flag.Int("buckets", 10, "the number of calibration buckets")
+	top := flag.Int("top", 20, "the number of players to show from the resulting leaderboard")
+	diff := flag.Bool("diff", false, "compare the recomputed ratings with the current ratings")
+	apply := flag.Bool("apply", false, "write the recomputed ratings to the database, after showing the diff")
+	flag.Parse()
+
+	// Ratings recomputed from a file may have nothing to do with the live ratings, so they are never written.
+	if *apply && *input != "" {
+		log.Fatal("-apply can't be used with -input, as only ratings replayed from the matches table can be written")
+	}
+
+	// Create the rater for the chosen rating system.
+	var rater elo.Rater
+	switch *system {
+	case "elo":
+		options := []elo.Option{elo.WithTenXMod(*tenXMod)}
+		if *k > 0 {
+			options = append(options, elo.WithK(*k))
+		} else {
+			options = append(options, elo.WithKPolicy(elo.KPolicy{
+				PlacementGames: settings.PlacementGames,
+				ProvisionalK:   settings.KFactorProvisional,
+				K:              settings.KFactorEstablished,
+				Bands: []elo.KBand{
+					{MinRating: settings.KFactorHighRatingThreshold, K: settings.KFactorHighRating},
+				},
+			}))
+		}
+
+		rater = elo.NewCalculator(options...)
+	case "glicko2":
+		rater = elo.Glicko2{Tau: *tau}
+	default:
+		log.Fatalf("rating system [ %v ] not recognized", *system)
+	}
+
+	// The database is only needed when reading matches from it, or comparing with and writing the current ratings.
+	if *input == "" || *diff || *apply {
+		database.Init()
+	}
+
+	// Read the matches.
+	var matches []elo.Match
+	var err error
+	if *input != "" {
+		matches, err = readMatches(*input)
+	} else {
+		matches, err = database.GetReplayMatches()
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Replay the matches, with every player starting at the same rating as a new account.
+	initial := elo.Rating{Value: float64(elo.Default), Deviation: elo.DefaultDeviation, Volatility: elo.DefaultVolatility}
+	result := elo.Replay(rater, initial, matches, *buckets)
+
+	// Report the metrics.
+	fmt.Printf("replayed %v matches between %v players through %v\n", result.Matches, len(result.Ratings), *system)
+	fmt.Printf("log loss: %.4f\n", result.LogLoss)
+	fmt.Printf("brier score: %.4f\n", result.Brier)
+	fmt.Println("\ncalibration (predicted range, predictions, mean predicted, mean actual):")
+	for _, bucket := range result.Calibration {
+		fmt.Printf("  %.2f-%.2f  %8v  %.3f  %.3f\n", bucket.Min, bucket.Max, bucket.Predictions, bucket.Predicted, bucket.Actual)
+	}
+
+	// Report the top of the resulting leaderboard.
+	ids := make([]uint64, 0, len(result.Ratings))
+	for id := range result.Ratings {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		return result.Ratings[ids[i]].Value > result.Ratings[ids[j]].Value
+	})
+
+	fmt.Println("\nleaderboard (rank, database ID, rating, deviation, games):")
+	for i, id := range ids {
+		if i >= *top {
+			break
+		}
+
+		rating := result.Ratings[id]
+		fmt.Printf("  %4v  %10v  %7.1f  %6.1f  %v\n", i+1, id, rating.Value, rating.Deviation, rating.Games)
+	}
+
+	// Exit here unless the recomputed ratings should be compared with the current ratings.
+	if !*diff && !*apply {
+		return
+	}
+
+	current, err := database.GetAllMatchStats()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Compare each player's current mmr with their recomputed mmr, in order of database ID. Players that did not play
+	// in any of the replayed matches are left unchanged.
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i] < ids[j]
+	})
+
+	changed := make(map[uint64]types.MatchStats)
+	fmt.Println("\ndiff (database ID, current mmr, recomputed mmr, delta):")
+	for _, id := range ids {
+		matchStats, ok := current[id]
+		if !ok {
+			continue
+		}
+
+		rating := result.Ratings[id]
+		recomputed := matchStats
+		recomputed.MMR = int16(math.Round(rating.Value))
+		recomputed.Deviation = rating.Deviation
+		recomputed.Volatility = rating.Volatility
+		if recomputed == matchStats {
+			continue
+		}
+
+		changed[id] = recomputed
+		fmt.Printf("  %10v  %6v  %6v  %+6v\n", id, matchStats.MMR, recomputed.MMR, recomputed.MMR-matchStats.MMR)
+	}
+
+	log.Printf("%v of %v players would change", len(changed), len(current))
+
+	// Warn about any adjustments to the live ratings that the replay does not reproduce, as the diff includes them.
+	comparable, err := warnAdjustments()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Exit here unless the recomputed ratings should actually be written. They are never written if they are not
+	// comparable with the live ratings, as that would undo the adjustments.
+	if !*apply {
+		return
+	}
+
+	if !comparable {
+		log.Fatal("refusing to -apply, as the recomputed ratings are not comparable with the live ratings")
+	}
+
+	updated, err := database.SetRatings(changed)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("wrote recomputed ratings for %v players", updated)
+}
+
+// warnAdjustments logs a warning for each kind of adjustment that has been made to the live ratings, which the replay does
+// not reproduce, and returns true if there are none.
+func warnAdjustments() (comparable bool, err error) {
+	adjustments, err := database.GetReplayAdjustments()
+	if err != nil {
+		return false, err
+	}
+
+	comparable = true
+	warn := func(count uint64, format string) {
+		if count > 0 {
+			log.Printf("warning: "+format+" - the replay does not reproduce this", count)
+			comparable = false
+		}
+	}
+
+	warn(adjustments.RolledOverSeasons, "%v seasons have been rolled over, soft resetting every rating")
+	warn(adjustments.Decays, "%v inactivity decays have been applied")
+	warn(adjustments.ReducedPairings, "%v matches had their gains reduced due to repeated pairings")
+	if !settings.UnconfirmedAccountsRanked {
+		warn(adjustments.UnconfirmedMatches, "%v finished matches involve accounts that have not confirmed their email address, and were not rated")
+	}
+	warn(adjustments.QueueRatings, "%v ratings exist in the unranked queue ladders, and unranked matches submitted without a match ID can't be told apart from ranked matches")
+
+	return comparable, nil
+}
+
+// readMatches reads matches from a JSONL file, with one match on each line. Blank lines are skipped.
+func readMatches(path string) (matches []elo.Match, err error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return matches, err
+	}
+
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		match := elo.Match{}
+		err = json.Unmarshal(scanner.Bytes(), &match)
+		if err != nil {
+			return matches, fmt.Errorf("line %v: %v", line, err)
+		}
+
+		if match.Winner > elo.Player2 {
+			return matches, fmt.Errorf("line %v: winner [ %v ] invalid", line, match.Winner)
+		}
+
+		matches = append(matches, match)
+	}
+
+	return matches, scanner.Err()
+}
@@ -20,6 +20,16 @@ const (
 	OffsetUpdateProfile         = 750
 	OffsetLeaderboards          = 800
 	OffsetGetMatchHistory       = 900
+	OffsetUpdatePrivilege       = 1000
+	OffsetReservedHandles       = 1100
+	OffsetChatFilter            = 1200
+	OffsetSignupChallenge       = 1300
+	OffsetConfirmEmail          = 1400
+	OffsetRatingHistory         = 1500
+	OffsetSeasons               = 1600
+	OffsetMatchQuality          = 1700
+	OffsetReviewQueue           = 1800
+	OffsetQueues                = 1900
 )
 
 // Success indicates that a request was successful.
@@ -32,6 +42,7 @@ const (
 	DatabaseError
 	CryptoRandomError
 	EmailSendFailure
+	RateLimitExceeded
 )
 
 // Create account handle errors.
@@ -42,6 +53,8 @@ const (
 	HandleFormat
 	HandleAlreadyInUse
 	HandleRude
+	HandleImpersonation
+	HandleReserved
 )
 
 // Create account email errors.
@@ -49,6 +62,8 @@ const (
 	EmailMissingOrWrongType B2ResultCode = iota + OffsetCreateAccountEmail
 	EmailFormat
 	EmailAlreadyInUse
+	EmailDisposable
+	EmailEquivalentInUse
 )
 
 // Create account password errors.
@@ -74,6 +89,11 @@ const (
 	Player1IDMissingOrWrongType B2ResultCode = iota + OffsetMMR
 	Player2IDMissingOrWrongType
 	WinnerMissingOrWrongType
+	PlayerEmailUnconfirmed
+	RatingSystemInvalid
+	MatchNotFound
+	IdempotencyKeyInvalid
+	MatchSubmissionConflict
 )
 
 // Get Profile errors.
@@ -102,3 +122,97 @@ const (
 	ProfileAvatarUpdateAuthTokenMissing
 	ProfileAvatarUpdateAvatarValueInvalid
 )
+
+// Update privilege errors.
+const (
+	PrivilegeUpdatePublicIDMissing B2ResultCode = iota + OffsetUpdatePrivilege
+	PrivilegeUpdatePublicIDNotFound
+	PrivilegeUpdatePrivilegeMissingOrWrongType
+	PrivilegeUpdatePrivilegeValueInvalid
+	PrivilegeUpdatePasswordConfirmationMissing
+	PrivilegeUpdatePasswordConfirmationIncorrect
+	PrivilegeUpdateLastServerAdmin
+)
+
+// Reserved handles errors.
+const (
+	ReservedHandleHandleMissingOrWrongType B2ResultCode = iota + OffsetReservedHandles
+	ReservedHandleMatchMissingOrWrongType
+	ReservedHandleMatchInvalid
+	ReservedHandlePatternInvalid
+	ReservedHandleReservedForNotFound
+	ReservedHandleIDMissing
+	ReservedHandleIDInvalid
+	ReservedHandleNotFound
+)
+
+// Chat filter errors.
+const (
+	ChatFilterLinesMissingOrWrongType B2ResultCode = iota + OffsetChatFilter
+	ChatFilterTooManyLines
+	ChatFilterLineTooLong
+	ChatFilterUnavailable
+)
+
+// Signup challenge errors.
+const (
+	SignupChallengeMissingOrWrongType B2ResultCode = iota + OffsetSignupChallenge
+	SignupChallengeNonceMissingOrWrongType
+	SignupChallengeInvalid
+	SignupChallengeExpired
+	SignupChallengeSolutionIncorrect
+	SignupChallengeUnavailable
+	SignupChallengeAlreadyUsed
+	SignupChallengeDifficultyTooLow
+)
+
+// Confirm email errors.
+const (
+	ConfirmEmailTokenMissing B2ResultCode = iota + OffsetConfirmEmail
+	ConfirmEmailTokenInvalid
+)
+
+// Get Rating History errors.
+const (
+	RatingHistoryPublicIDMissing B2ResultCode = iota + OffsetRatingHistory
+	RatingHistoryPublicIDNotFound
+	RatingHistoryRangeFromInvalid
+	RatingHistoryRangeCountInvalid
+	RatingHistoryPointsInvalid
+)
+
+// Season errors.
+const (
+	SeasonNameMissingOrWrongType B2ResultCode = iota + OffsetSeasons
+	SeasonStartMissingOrWrongType
+	SeasonEndMissingOrWrongType
+	SeasonNameInvalid
+	SeasonDatesInvalid
+	SeasonOverlaps
+	SeasonIDMissing
+	SeasonIDInvalid
+	SeasonNotFound
+	SeasonNotEnded
+	SeasonAlreadyRolledOver
+	SeasonStandingsUnavailable
+)
+
+// Match quality errors.
+const (
+	MatchQualityPublicIDsMissing B2ResultCode = iota + OffsetMatchQuality
+	MatchQualityPublicIDsInvalid
+	MatchQualityPublicIDNotFound
+)
+
+// Review queue errors.
+const (
+	ReviewFlagIDMissing B2ResultCode = iota + OffsetReviewQueue
+	ReviewFlagIDInvalid
+	ReviewFlagNotFound
+)
+
+// Queue errors.
+const (
+	QueueInvalid B2ResultCode = iota + OffsetQueues
+	QueueSeasonUnsupported
+)
@@ -0,0 +1,43 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// ReviewReason is a string typedef used for the enumeration of the reasons that a pair of players can be flagged for
+// review, as stored in the review queue.
+type ReviewReason string
+
+// Review reasons.
+const (
+	ReviewRepeatedLosses ReviewReason = "repeated_losses"
+	ReviewShortMatches   ReviewReason = "short_matches"
+)
+
+// PairingStats are the stats for the recent matches between a pair of players, for internal use as a dumb container.
+// Short is the number of matches that were shorter than the short match threshold.
+type PairingStats struct {
+	Matches     uint32
+	Player1Wins uint32
+	Player2Wins uint32
+	Draws       uint32
+	Short       uint32
+}
+
+// Pairing is a match between a pair of players, for internal use as a dumb container. Duration is the length of the
+// match in seconds, if known, Multiplier is the multiplier that was applied to rating gains from the match, and Flags
+// are any review flags raised by the match.
+type Pairing struct {
+	Duration   *uint32
+	Multiplier float64
+	Flags      []ReviewFlag
+}
+
+// ReviewFlag is a player flagged for review, along with the opponent they were flagged with.
+type ReviewFlag struct {
+	Player   uint64
+	Opponent uint64
+	Reason   ReviewReason
+	Details  string
+}
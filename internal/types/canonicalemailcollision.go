@@ -0,0 +1,14 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// CanonicalEmailCollision is a group of existing accounts whose email addresses share the same canonical form, for
+// internal use as a dumb container. Database IDs are in ascending order.
+type CanonicalEmailCollision struct {
+	Canonical   string
+	DatabaseIDs []uint64
+	Emails      []string
+}
@@ -0,0 +1,23 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+import "time"
+
+// DecayCandidate is a player whose rating may decay, for internal use as a dumb container. Inactive is how long it has
+// been since they last played a ranked match.
+type DecayCandidate struct {
+	DatabaseID uint64
+	MatchStats MatchStats
+	Inactive   time.Duration
+}
+
+// DecayResult is a summary of a run of the decay job, which is logged. Checked is the number of players that had been
+// inactive for long enough that their rating might decay, and Decayed is the number whose rating changed.
+type DecayResult struct {
+	Checked int `json:"checked"`
+	Decayed int `json:"decayed"`
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// HandleKeyCollision is a group of existing accounts whose handles share the same canonical handle key, for
+// internal use as a dumb container.
+type HandleKeyCollision struct {
+	Key         string
+	DatabaseIDs []uint64
+	Handles     []string
+}
@@ -6,10 +6,14 @@
 package types
 
 // MatchStats is a wrapper for a players match stats, for internal use as a
-// dumb container.
+// dumb container. Tier is empty if the player has not been placed into a tier.
 type MatchStats struct {
-	MMR    int16
-	Wins   uint32
-	Draws  uint32
-	Losses uint32
+	MMR        int16
+	Deviation  float64
+	Volatility float64
+	Wins       uint32
+	Draws      uint32
+	Losses     uint32
+	Tier       string
+	Division   uint8
 }
@@ -0,0 +1,16 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// MatchSubmission is a processed match result submission, recorded so that a retry of the submission returns the
+// original result, rather than rating the match again. Keys identify the submission - it is recorded under each of them,
+// so that a retry matching any one of them is detected. PayloadHash is a hash of the request body, and Response is the
+// JSON encoded payload of the original response.
+type MatchSubmission struct {
+	Keys        []string
+	PayloadHash string
+	Response    []byte
+}
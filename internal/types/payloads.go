@@ -22,20 +22,32 @@ type CreateAccountResponsePayload struct {
 	Handle string `json:"handle"`
 }
 
-// ProfileResponsePayload is a container for the response payload of a successful profile get request.
+// ProfileResponsePayload is a container for the response payload of a successful profile get request. The rating and
+// w/d/l are from the ladder for the queue, and the tier, division, and season stats are only set for the ranked queue.
 type ProfileResponsePayload struct {
-	Avatar      uint8     `json:"avatar"`
-	MMR         int16     `json:"mmr"`
-	Wins        uint32    `json:"wins"`
-	Draws       uint32    `json:"draws"`
-	Losses      uint32    `json:"losses"`
-	WinRatio    float32   `json:"winratio"`
-	RankedTotal int64     `json:"rankedtotal"`
-	Created     time.Time `json:"created"`
+	Queue        string          `json:"queue"`
+	Avatar       uint8           `json:"avatar"`
+	MMR          int16           `json:"mmr"`
+	Deviation    float64         `json:"deviation"`
+	Wins         uint32          `json:"wins"`
+	Draws        uint32          `json:"draws"`
+	Losses       uint32          `json:"losses"`
+	WinRatio     float32         `json:"winratio"`
+	RankedTotal  int64           `json:"rankedtotal"`
+	Provisional  bool            `json:"provisional"`
+	Tier         string          `json:"tier"`
+	Division     uint8           `json:"division"`
+	SeasonWins   uint32          `json:"seasonwins"`
+	SeasonDraws  uint32          `json:"seasondraws"`
+	SeasonLosses uint32          `json:"seasonlosses"`
+	Season       *SeasonStanding `json:"season,omitempty"`
+	Created      time.Time       `json:"created"`
 }
 
-// LeaderboardResponsePayload is a container for the response payload of a successful leaderboards get request.
+// LeaderboardResponsePayload is a container for the response payload of a successful leaderboards get request, for
+// the ladder of the queue.
 type LeaderboardResponsePayload struct {
+	Queue        string           `json:"queue"`
 	Leaderboards []LeaderboardRow `json:"leaderboards"`
 	User         LeaderboardRow   `json:"user"`
 }
@@ -53,6 +65,31 @@ type LeaderboardRow struct {
 	PublicID    string  `json:"pid"`
 	Rank        uint64  `json:"rank"`
 	OutOf       uint64  `json:"outof"`
+	Provisional bool    `json:"provisional"`
+	Tier        string  `json:"tier"`
+	Division    uint8   `json:"division"`
+}
+
+// MMRUpdateResponsePayload is a container for the response payload of a successful MMR update request. GainMultiplier
+// is the multiplier that was applied to rating gains, which is less than 1 if the players have played each other
+// repeatedly in the ranked queue.
+type MMRUpdateResponsePayload struct {
+	Queue          string    `json:"queue"`
+	Player1        MMRChange `json:"player1"`
+	Player2        MMRChange `json:"player2"`
+	GainMultiplier float64   `json:"gainmultiplier"`
+}
+
+// MMRChange is the change in a single player's MMR after a match, and their tier and division after the match.
+// Promoted or demoted is set if the match moved the player into a higher or lower division.
+type MMRChange struct {
+	Before   int16  `json:"before"`
+	After    int16  `json:"after"`
+	Delta    int16  `json:"delta"`
+	Tier     string `json:"tier"`
+	Division uint8  `json:"division"`
+	Promoted bool   `json:"promoted"`
+	Demoted  bool   `json:"demoted"`
 }
 
 // MatchHistory is a container for the response payload of a successful match history get request.
@@ -60,14 +97,157 @@ type MatchHistory struct {
 	Rows []MatchHistoryRow `json:"rows"`
 }
 
-// MatchHistoryRow is a single row in a players match history.
+// MatchHistoryRow is a single row in a players match history. The queue the match was rated in, and the ratings before
+// and after the match (in the ladder for that queue), are null for matches whose result was submitted without a match
+// ID.
 type MatchHistoryRow struct {
-	MatchID         uint64    `json:"matchid"`
-	Player1Handle   string    `json:"player1handle"`
-	Player1PublicID string    `json:"player1pid"`
-	Player2Handle   string    `json:"player2handle"`
-	Player2PublicID string    `json:"player2pid"`
-	WinnerHandle    string    `json:"winnerhandle"`
-	WinnerPublicID  string    `json:"winnerpid"`
-	EndTime         time.Time `json:"endtime"`
+	MatchID          uint64    `json:"matchid"`
+	Player1Handle    string    `json:"player1handle"`
+	Player1PublicID  string    `json:"player1pid"`
+	Player2Handle    string    `json:"player2handle"`
+	Player2PublicID  string    `json:"player2pid"`
+	WinnerHandle     string    `json:"winnerhandle"`
+	WinnerPublicID   string    `json:"winnerpid"`
+	EndTime          time.Time `json:"endtime"`
+	Queue            *string   `json:"queue"`
+	Player1MMRBefore *int16    `json:"player1mmrbefore"`
+	Player1MMRAfter  *int16    `json:"player1mmrafter"`
+	Player2MMRBefore *int16    `json:"player2mmrbefore"`
+	Player2MMRAfter  *int16    `json:"player2mmrafter"`
+}
+
+// RatingHistoryResponsePayload is a container for the response payload of a successful rating history get request.
+// Queue is the queue whose ladder the history is for. Total is the number of points in the player's entire rating
+// history for the queue, regardless of how many were returned.
+type RatingHistoryResponsePayload struct {
+	Queue  string               `json:"queue"`
+	Total  uint64               `json:"total"`
+	Points []RatingHistoryPoint `json:"points"`
+}
+
+// RatingHistoryPoint is a single change in a player's rating. MatchID is null if the change was not caused by a match,
+// or if the match ID was not recorded.
+type RatingHistoryPoint struct {
+	MatchID   *uint64            `json:"matchid"`
+	Reason    RatingChangeReason `json:"reason"`
+	MMRBefore int16              `json:"mmrbefore"`
+	MMRAfter  int16              `json:"mmrafter"`
+	Deviation float64            `json:"deviation"`
+	Time      time.Time          `json:"time"`
+}
+
+// SeasonsResponsePayload is a container for the response payload of a successful seasons get request.
+type SeasonsResponsePayload struct {
+	Seasons []Season `json:"seasons"`
+}
+
+// Season is a single ranked season. RolledOver is null until the season's final standings have been archived.
+type Season struct {
+	ID         uint64     `json:"id"`
+	Name       string     `json:"name"`
+	Start      time.Time  `json:"start"`
+	End        time.Time  `json:"end"`
+	RolledOver *time.Time `json:"rolledover"`
+}
+
+// SeasonResponsePayload is a container for the response payload of a successful season schedule request.
+type SeasonResponsePayload struct {
+	ID uint64 `json:"id"`
+}
+
+// SeasonStanding is a player's final standing in a season.
+type SeasonStanding struct {
+	SeasonID uint64 `json:"seasonid"`
+	Rank     uint64 `json:"rank"`
+	OutOf    uint64 `json:"outof"`
+	MMR      int16  `json:"mmr"`
+	Wins     uint32 `json:"wins"`
+	Draws    uint32 `json:"draws"`
+	Losses   uint32 `json:"losses"`
+}
+
+// MatchQualityResponsePayload is a container for the response payload of a successful match quality request. There is
+// a pair for every combination of the requested players, in the order that they were requested.
+type MatchQualityResponsePayload struct {
+	Pairs []MatchQualityPair `json:"pairs"`
+}
+
+// MatchQualityPair is the predicted outcome of a match between two players. Quality is from 0 (one player is certain
+// to win) to 1 (both players are equally likely to win).
+type MatchQualityPair struct {
+	Player1          string         `json:"player1"`
+	Player2          string         `json:"player2"`
+	Player1WinChance float64        `json:"player1winchance"`
+	Player2WinChance float64        `json:"player2winchance"`
+	Quality          float64        `json:"quality"`
+	Player1Win       ExpectedChange `json:"player1win"`
+	Draw             ExpectedChange `json:"draw"`
+	Player2Win       ExpectedChange `json:"player2win"`
+}
+
+// ExpectedChange is the change in MMR for both players, if a match between them had a particular outcome.
+type ExpectedChange struct {
+	Player1 int16 `json:"player1"`
+	Player2 int16 `json:"player2"`
+}
+
+// ReviewQueueResponsePayload is a container for the response payload of a successful review queue get request.
+type ReviewQueueResponsePayload struct {
+	Flags []ReviewQueueEntry `json:"flags"`
+}
+
+// ReviewQueueEntry is a single unresolved flag in the review queue.
+type ReviewQueueEntry struct {
+	ID               uint64       `json:"id"`
+	PlayerHandle     string       `json:"playerhandle"`
+	PlayerPublicID   string       `json:"playerpid"`
+	OpponentHandle   string       `json:"opponenthandle"`
+	OpponentPublicID string       `json:"opponentpid"`
+	Reason           ReviewReason `json:"reason"`
+	Details          string       `json:"details"`
+	Created          time.Time    `json:"created"`
+}
+
+// ChatFilterResponsePayload is a container for the response payload of a successful chat filter request. The lines are
+// in the same order as in the request.
+type ChatFilterResponsePayload struct {
+	Lines []ChatFilterLine `json:"lines"`
+}
+
+// ChatFilterLine represents a single filtered line of chat.
+type ChatFilterLine struct {
+	Text  string           `json:"text"`
+	Spans []ChatFilterSpan `json:"spans"`
+}
+
+// ChatFilterSpan represents a range of runes in a line of chat that matched a profane term. Start and end are rune
+// offsets into the original line (start inclusive, end exclusive).
+type ChatFilterSpan struct {
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	Severity uint8  `json:"severity"`
+	Reason   string `json:"reason"`
+	Masked   bool   `json:"masked"`
+}
+
+// AvailabilityResponsePayload is a container for the response payload of a successful availability check request. If
+// the handle (and email, if specified) can be used to create an account, failures is empty.
+type AvailabilityResponsePayload struct {
+	Available bool                  `json:"available"`
+	Failures  []AvailabilityFailure `json:"failures"`
+}
+
+// AvailabilityFailure represents a single rule that a handle or email failed during an availability check, with the
+// same code and info that CreateAccount would return for it.
+type AvailabilityFailure struct {
+	Code B2ResultCode `json:"code"`
+	Info string       `json:"info"`
+}
+
+// SignupChallengeResponsePayload is a container for the response payload of a successful signup challenge request. The
+// client should find a nonce such that the SHA-256 hash of "{challenge}:{nonce}" starts with difficulty zero bits.
+type SignupChallengeResponsePayload struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty uint8     `json:"difficulty"`
+	Expires    time.Time `json:"expires"`
 }
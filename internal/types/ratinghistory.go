@@ -0,0 +1,18 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// RatingChangeReason is a string typedef used for the enumeration of the reasons that a player's rating can change, as
+// stored in the rating history.
+type RatingChangeReason string
+
+// Rating change reasons.
+const (
+	RatingChangeMatch  RatingChangeReason = "match"
+	RatingChangeSeason RatingChangeReason = "season"
+	RatingChangeDecay  RatingChangeReason = "decay"
+	RatingChangeReplay RatingChangeReason = "replay"
+)
@@ -0,0 +1,23 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// ReplayAdjustments counts the adjustments that have been made to the live ratings, which replaying the matches through
+// a rating system does not reproduce. If any have been made, the replayed ratings are not comparable with the live
+// ratings, and should not replace them.
+//
+// RolledOverSeasons is the number of seasons that have been rolled over (soft resetting every rating), Decays is the
+// number of inactivity decays recorded in the rating history, ReducedPairings is the number of rated matches whose gains
+// were reduced due to repeated pairings, UnconfirmedMatches is the number of finished matches involving a player that
+// has not confirmed their email address, and QueueRatings is the number of ratings in the unranked queue ladders -
+// unranked matches whose result was submitted without a match ID can't be told apart from ranked matches.
+type ReplayAdjustments struct {
+	RolledOverSeasons  uint64
+	Decays             uint64
+	ReducedPairings    uint64
+	UnconfirmedMatches uint64
+	QueueRatings       uint64
+}
@@ -69,3 +69,15 @@ func MakeLambdaResponse(httpCode HTTPCode, b2code B2ResultCode, payload interfac
 
 	return lambdaResponse
 }
+
+// PrivilegeUpdateResponsePayload is a container for the response payload of a successful privilege update request.
+type PrivilegeUpdateResponsePayload struct {
+	PublicID     string `json:"pid"`
+	OldPrivilege uint8  `json:"oldprivilege"`
+	NewPrivilege uint8  `json:"newprivilege"`
+}
+
+// ReservedHandleResponsePayload is a container for the response payload of a successful reserved handle add request.
+type ReservedHandleResponsePayload struct {
+	ID uint64 `json:"id"`
+}
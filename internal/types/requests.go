@@ -5,23 +5,37 @@
 // Package types defines types and contstants for this application.
 package types
 
-import "github.com/6a/blade-ii-api/pkg/elo"
+import (
+	"time"
+
+	"github.com/6a/blade-ii-api/pkg/elo"
+)
 
 // Structs defined here should also include json serialization hints. They are used to parse request
 // bodies that contain data.
 
-// UserCreationRequest describes the request body format for a new user request.
+// UserCreationRequest describes the request body format for a new user request. The challenge should be one issued by
+// the signup challenge route, and the nonce should solve it.
 type UserCreationRequest struct {
-	Handle   *string `json:"handle"`
-	Email    *string `json:"email"`
-	Password *string `json:"password"`
+	Handle    *string `json:"handle"`
+	Email     *string `json:"email"`
+	Password  *string `json:"password"`
+	Challenge *string `json:"challenge"`
+	Nonce     *string `json:"nonce"`
 }
 
-// MMRUpdateRequest describes the request body format for an MMR update request.
+// MMRUpdateRequest describes the request body format for an MMR update request. MatchID is optional, and is recorded in
+// the rating history of both players if specified, for ranked matches. It also identifies the submission, so that
+// retries are only processed once, unless an Idempotency-Key header is specified instead. Duration is optional, and is
+// the length of the match in seconds, used to detect rating farming. Queue is optional, and is the name of the queue
+// that the match was played in, which defaults to the ranked queue.
 type MMRUpdateRequest struct {
 	Player1ID *uint64     `json:"player1id"`
 	Player2ID *uint64     `json:"player2id"`
 	Winner    *elo.Player `json:"winner"`
+	MatchID   *uint64     `json:"matchid"`
+	Duration  *uint32     `json:"duration"`
+	Queue     *string     `json:"queue"`
 }
 
 // AvatarUpdateRequest describes the request body format for an avatar update request.
@@ -29,3 +43,33 @@ type AvatarUpdateRequest struct {
 	Avatar    *uint8  `json:"avatar"`
 	AuthToken *string `json:"authtoken"`
 }
+
+// PrivilegeUpdateRequest describes the request body format for a privilege update request. The password
+// confirmation is only required when the update raises the privilege level of the target account.
+type PrivilegeUpdateRequest struct {
+	Privilege            *uint8  `json:"privilege"`
+	PasswordConfirmation *string `json:"passwordconfirmation"`
+}
+
+// ReservedHandleRequest describes the request body format for a request to add a reserved handle. The reservedfor field
+// is optional, and if present should be the public ID of the only account that is allowed to use the handle.
+type ReservedHandleRequest struct {
+	Handle      *string              `json:"handle"`
+	Match       *ReservedHandleMatch `json:"match"`
+	ReservedFor *string              `json:"reservedfor"`
+}
+
+// ChatFilterRequest describes the request body format for a chat filter request. The private field is optional, and
+// should be true if the lines are from a private context (such as a private chat), where mild swearing is allowed.
+type ChatFilterRequest struct {
+	Lines   *[]string `json:"lines"`
+	Private *bool     `json:"private"`
+}
+
+// SeasonRequest describes the request body format for a request to schedule a season. Start and end should be RFC 3339
+// timestamps.
+type SeasonRequest struct {
+	Name  *string    `json:"name"`
+	Start *time.Time `json:"start"`
+	End   *time.Time `json:"end"`
+}
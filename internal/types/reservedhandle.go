@@ -0,0 +1,25 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package types defines types and contstants for this application.
+package types
+
+// ReservedHandleMatch is a uint8 typedef used for the enumeration of the ways in which a reserved handle can match a handle.
+type ReservedHandleMatch uint8
+
+// Reserved handle match types.
+const (
+	ReservedHandleExact ReservedHandleMatch = iota
+	ReservedHandlePrefix
+	ReservedHandlePattern
+)
+
+// ReservedHandle is a single entry in the reserved handles store, for internal use as a dumb container. If ReservedFor
+// is not nil, it is the database ID of the only account that is allowed to use handles matching this entry.
+type ReservedHandle struct {
+	ID          uint64
+	Handle      string
+	Match       ReservedHandleMatch
+	ReservedFor *uint64
+}
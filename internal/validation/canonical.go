@@ -0,0 +1,34 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package validation is a utility package that contains various validation regex patterns.
+package validation
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// CanonicalHandle returns the canonical key for the specified handle. Two handles with the same key are considered to be
+// the same handle for the purposes of uniqueness and lookup, while the handle itself is kept as the user typed it for display.
+//
+// The key is created by applying NFKC normalization (so full-width "Ｒｅａｎ" becomes "Rean"), case folding (so "Rean"
+// becomes "rean"), and collapsing all runs of whitespace into a single space, with leading and trailing whitespace removed.
+func CanonicalHandle(handle string) string {
+
+	// Normalize the handle so that compatibility characters such as full-width latin characters and numbers,
+	// and the full-width space, are replaced with their standard forms.
+	key := norm.NFKC.String(handle)
+
+	// Fold the case of the handle. Note that a new caser is created each time as they are not safe for concurrent use.
+	key = cases.Fold().String(key)
+
+	// Case folding can produce strings that are no longer normalized, so normalize again.
+	key = norm.NFKC.String(key)
+
+	// Collapse all runs of whitespace into a single space.
+	return strings.Join(strings.Fields(key), " ")
+}
@@ -0,0 +1,132 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package validation is a utility package that contains various validation regex patterns.
+package validation
+
+import (
+	_ "embed"
+	"strings"
+)
+
+// emailProvider describes how a mail provider treats the local part (before the "@") of its addresses, so that
+// addresses which are delivered to the same inbox can be given the same canonical form.
+type emailProvider struct {
+
+	// domain is the canonical domain for the provider, for providers with more than one domain.
+	domain string
+
+	// ignoresDots is true if the provider ignores dots in the local part, so "a.b" is the same inbox as "ab".
+	ignoresDots bool
+
+	// tagSeparator is the character that starts a sub-address tag, which is ignored for delivery ("name+tag").
+	tagSeparator string
+}
+
+// emailProviders are the providers with known sub-addressing rules, by domain.
+var emailProviders = map[string]emailProvider{
+	"gmail.com":      {domain: "gmail.com", ignoresDots: true, tagSeparator: "+"},
+	"googlemail.com": {domain: "gmail.com", ignoresDots: true, tagSeparator: "+"},
+	"outlook.com":    {domain: "outlook.com", tagSeparator: "+"},
+	"hotmail.com":    {domain: "hotmail.com", tagSeparator: "+"},
+	"live.com":       {domain: "live.com", tagSeparator: "+"},
+	"icloud.com":     {domain: "icloud.com", tagSeparator: "+"},
+	"me.com":         {domain: "icloud.com", tagSeparator: "+"},
+	"mac.com":        {domain: "icloud.com", tagSeparator: "+"},
+	"fastmail.com":   {domain: "fastmail.com", tagSeparator: "+"},
+	"protonmail.com": {domain: "protonmail.com", tagSeparator: "+"},
+	"proton.me":      {domain: "protonmail.com", tagSeparator: "+"},
+	"pm.me":          {domain: "protonmail.com", tagSeparator: "+"},
+	"yahoo.com":      {domain: "yahoo.com", tagSeparator: "-"},
+}
+
+// disposableDomainList is the embedded list of disposable email domains, one per line.
+//
+//go:embed disposable_domains.txt
+var disposableDomainList string
+
+// disposableDomains is the set of domains in the disposable domain list.
+var disposableDomains = parseDomainList(disposableDomainList)
+
+// CanonicalEmail returns the canonical form of the specified email address. Two addresses with the same canonical form
+// are delivered to the same inbox, so are considered to be the same address for the purposes of uniqueness.
+//
+// The canonical form is the address in lower case, with the domain's trailing dot removed. For providers with known
+// sub-addressing rules, sub-address tags (such as "+1" in "name+1@gmail.com") are removed, dots are removed if the
+// provider ignores them, and alternative domains are replaced (so "googlemail.com" becomes "gmail.com").
+func CanonicalEmail(email string) string {
+
+	// Split the address at the last "@". Addresses without one are returned in lower case, as they have no domain.
+	email = strings.ToLower(strings.TrimSpace(email))
+	separator := strings.LastIndex(email, "@")
+	if separator == -1 {
+		return email
+	}
+
+	local, domain := email[:separator], strings.TrimSuffix(email[separator+1:], ".")
+
+	// Apply the rules for the provider, if it is known.
+	if provider, ok := emailProviders[domain]; ok {
+		if index := strings.Index(local, provider.tagSeparator); index > 0 {
+			local = local[:index]
+		}
+
+		if provider.ignoresDots {
+			local = strings.ReplaceAll(local, ".", "")
+		}
+
+		domain = provider.domain
+	}
+
+	return local + "@" + domain
+}
+
+// EmailDomain returns the domain of the specified email address, in lower case and without a trailing dot.
+func EmailDomain(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	return strings.TrimSuffix(email[strings.LastIndex(email, "@")+1:], ".")
+}
+
+// DisposableEmailDomain returns true if the domain, or any domain it is a subdomain of, is in the disposable domain list.
+func DisposableEmailDomain(domain string) bool {
+	for _, candidate := range ParentDomains(domain) {
+		if disposableDomains[candidate] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParentDomains returns the domain, followed by every domain that it is a subdomain of, so "a.b.com" returns
+// "a.b.com", "b.com" and "com".
+func ParentDomains(domain string) (domains []string) {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	for domain != "" {
+		domains = append(domains, domain)
+
+		separator := strings.Index(domain, ".")
+		if separator == -1 {
+			break
+		}
+
+		domain = domain[separator+1:]
+	}
+
+	return domains
+}
+
+// parseDomainList returns the set of domains in the list, which has one domain per line. Empty lines, and lines
+// starting with "#", are ignored.
+func parseDomainList(list string) map[string]bool {
+	domains := make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line != "" && !strings.HasPrefix(line, "#") {
+			domains[line] = true
+		}
+	}
+
+	return domains
+}
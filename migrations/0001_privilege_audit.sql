@@ -0,0 +1,13 @@
+-- Audit trail for privilege changes made through the UpdatePrivilege route.
+-- The table name should match the "db_table_privilege_audit" environment variable.
+CREATE TABLE IF NOT EXISTS `privilege_audit` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `actor` BIGINT UNSIGNED NOT NULL,
+  `target` BIGINT UNSIGNED NOT NULL,
+  `old_privilege` TINYINT UNSIGNED NOT NULL,
+  `new_privilege` TINYINT UNSIGNED NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `target_idx` (`target`),
+  INDEX `actor_idx` (`actor`)
+);
@@ -0,0 +1,13 @@
+-- Canonical handle keys (NFKC normalized, case folded, whitespace collapsed) for uniqueness and lookup.
+-- The users table name should match the "db_table_users" environment variable.
+--
+-- 1. Add the nullable column and its unique index (MySQL allows multiple NULL values in a unique index).
+ALTER TABLE `users`
+  ADD COLUMN `handle_key` VARCHAR(80) NULL AFTER `handle`,
+  ADD UNIQUE INDEX `handle_key_UNIQUE` (`handle_key`);
+
+-- 2. Run internal/tools/backfill_handle_keys to report collisions between existing accounts. Once every
+--    collision has been resolved, run it again with -apply to write the keys.
+--
+-- 3. Once every row has a key, run 0017_handle_key_required.sql to make the column required. It is kept separate so
+--    that this migration can run before the backfill.
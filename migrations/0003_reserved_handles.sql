@@ -0,0 +1,27 @@
+-- Reserved handles store, checked by CreateAccount and managed through the reserved handle admin routes.
+-- The table name should match the "db_table_reserved_handles" environment variable.
+--
+-- match: 0 = exact, 1 = prefix, 2 = regular expression (matched against the canonical handle key).
+-- reserved_for: the database ID of the only account allowed to use matching handles, or NULL for nobody.
+CREATE TABLE IF NOT EXISTS `reserved_handles` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `handle` VARCHAR(255) NOT NULL,
+  `match` TINYINT UNSIGNED NOT NULL DEFAULT 0,
+  `reserved_for` BIGINT UNSIGNED NULL,
+  `created_by` BIGINT UNSIGNED NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
+
+INSERT INTO `reserved_handles` (`handle`, `match`, `created_by`) VALUES
+  ('admin', 1, 0),
+  ('administrator', 0, 0),
+  ('gm', 0, 0),
+  ('gamemaster', 0, 0),
+  ('moderator', 1, 0),
+  ('system', 0, 0),
+  ('staff', 1, 0),
+  ('support', 0, 0),
+  ('blade ii', 1, 0),
+  ('^運営', 2, 0),
+  ('^管理', 2, 0);
@@ -0,0 +1,14 @@
+-- Profanity terms, loaded alongside the word lists embedded in pkg/profanity, and reloaded periodically so that terms
+-- can be added without a redeploy. The table name should match the "db_table_profanity_terms" environment variable.
+--
+-- mode: substring, word, exact, or allow (an allowlist word, with no severity).
+-- severity: block, flag, or private (allowed in private contexts only).
+CREATE TABLE IF NOT EXISTS `profanity_terms` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `locale` VARCHAR(16) NOT NULL,
+  `term` VARCHAR(255) NOT NULL,
+  `mode` VARCHAR(16) NOT NULL DEFAULT 'substring',
+  `severity` VARCHAR(16) NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`)
+);
@@ -0,0 +1,10 @@
+-- Fixed window request counters, used to rate limit routes that could otherwise be used for bulk enumeration.
+-- The table name should match the "db_table_rate_limits" environment variable.
+--
+-- key: the route and the identity of the caller (such as their IP address).
+CREATE TABLE IF NOT EXISTS `rate_limits` (
+  `key` VARCHAR(128) NOT NULL,
+  `window_start` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `count` INT UNSIGNED NOT NULL DEFAULT 0,
+  PRIMARY KEY (`key`)
+);
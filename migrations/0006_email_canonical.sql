@@ -0,0 +1,19 @@
+-- Canonical email addresses (lower case, with provider specific sub-address tags and dots removed) for uniqueness,
+-- and a table of blocked email domains that supplements the disposable domain list embedded in the application.
+-- The table names should match the "db_table_users" and "db_table_blocked_email_domains" environment variables.
+--
+-- 1. Add the nullable column and its unique index (MySQL allows multiple NULL values in a unique index).
+ALTER TABLE `users`
+  ADD COLUMN `email_canonical` VARCHAR(255) NULL AFTER `email`,
+  ADD UNIQUE INDEX `email_canonical_UNIQUE` (`email_canonical`);
+
+-- 2. Run internal/tools/backfill_canonical_emails to report existing accounts that share a canonical email, and then
+--    run it again with -apply to write the canonical emails. Colliding accounts other than the oldest are left NULL,
+--    so the column stays nullable.
+
+-- Blocked email domains. Subdomains of each domain are also blocked.
+CREATE TABLE IF NOT EXISTS `blocked_email_domains` (
+  `domain` VARCHAR(255) NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`domain`)
+);
@@ -0,0 +1,20 @@
+-- Confirmed email state for each account. Accounts are created unconfirmed, and are confirmed with the token sent to
+-- their email address when they were created. What unconfirmed accounts can do is decided by the settings package.
+-- The table name should match the "db_table_users" environment variable.
+ALTER TABLE `users`
+  ADD COLUMN `email_confirmed` TINYINT(1) NOT NULL DEFAULT 0 AFTER `email_canonical`;
+
+-- Accounts that existed before confirmation was checked could never have confirmed their email address. Only those with
+-- a real signal of use are grandfathered in as confirmed: accounts that have played at least one ranked match, and were
+-- created before the cutoff. The cutoff excludes the recent throwaway accounts that confirmation is meant to stop, and
+-- should be set to before any suspected burst of throwaway signups. The profiles table name should match the
+-- "db_table_profiles" environment variable.
+--
+-- Every other existing account is left unconfirmed, and is treated like a new unconfirmed account. It can be confirmed
+-- with its email confirmation token through the ConfirmEmail route, if the token has not expired.
+SET @grandfather_cutoff = DATE_SUB(NOW(), INTERVAL 30 DAY);
+
+UPDATE `users` `u`
+  JOIN `profiles` `p` ON `p`.`id` = `u`.`id`
+  SET `u`.`email_confirmed` = 1
+  WHERE `p`.`ranked_total` > 0 AND `p`.`created` < @grandfather_cutoff;
@@ -0,0 +1,6 @@
+-- Rating deviation and volatility for each profile, used by the Glicko-2 rating system. The elo rating system leaves
+-- them unchanged. The defaults match elo.DefaultDeviation and elo.DefaultVolatility.
+-- The table name should match the "db_table_profiles" environment variable.
+ALTER TABLE `profiles`
+  ADD COLUMN `rating_deviation` DOUBLE NOT NULL DEFAULT 350 AFTER `mmr`,
+  ADD COLUMN `rating_volatility` DOUBLE NOT NULL DEFAULT 0.06 AFTER `rating_deviation`;
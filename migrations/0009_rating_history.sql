@@ -0,0 +1,19 @@
+-- Every change to a player's rating, with the values before and after the change, so that players can see how their
+-- rating has moved over time. The table name should match the "db_table_rating_history" environment variable.
+--
+-- player: the database ID of the player whose rating changed.
+-- match_id: the ID of the match that caused the change, if it was caused by a match (and the match ID was submitted).
+-- reason: what caused the change, such as "match".
+CREATE TABLE IF NOT EXISTS `rating_history` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `player` BIGINT UNSIGNED NOT NULL,
+  `match_id` BIGINT UNSIGNED NULL,
+  `reason` VARCHAR(16) NOT NULL,
+  `mmr_before` SMALLINT NOT NULL,
+  `mmr_after` SMALLINT NOT NULL,
+  `deviation_before` DOUBLE NOT NULL,
+  `deviation_after` DOUBLE NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `player_created` (`player`, `created`, `id`)
+);
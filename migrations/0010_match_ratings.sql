@@ -0,0 +1,8 @@
+-- Each player's rating before and after a match, so that players can see how much each past match was worth. They are
+-- set when the match result is submitted with its match ID, and are NULL for older matches.
+-- The table name should match the "db_table_matches" environment variable.
+ALTER TABLE `matches`
+  ADD COLUMN `player1_mmr_before` SMALLINT NULL,
+  ADD COLUMN `player1_mmr_after` SMALLINT NULL,
+  ADD COLUMN `player2_mmr_before` SMALLINT NULL,
+  ADD COLUMN `player2_mmr_after` SMALLINT NULL;
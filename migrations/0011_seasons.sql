@@ -0,0 +1,36 @@
+-- Ranked seasons, and the final standings for each season once it has been rolled over. Wins, draws, and losses for the
+-- current season are counted in the profiles table alongside the lifetime counts, and are reset on rollover.
+-- The table names should match the "db_table_seasons", "db_table_season_standings", and "db_table_profiles"
+-- environment variables.
+--
+-- rolled_over: when the season's final standings were archived and ratings were soft reset, or NULL if not yet.
+CREATE TABLE IF NOT EXISTS `seasons` (
+  `id` INT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `name` VARCHAR(64) NOT NULL,
+  `start` DATETIME NOT NULL,
+  `end` DATETIME NOT NULL,
+  `rolled_over` DATETIME NULL,
+  `created_by` BIGINT UNSIGNED NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `start_end` (`start`, `end`)
+);
+
+-- player: the database ID of the player.
+-- final_rank: the player's rank among the players who played in the season, when it was rolled over.
+CREATE TABLE IF NOT EXISTS `season_standings` (
+  `season` INT UNSIGNED NOT NULL,
+  `player` BIGINT UNSIGNED NOT NULL,
+  `final_rank` BIGINT UNSIGNED NOT NULL,
+  `mmr` SMALLINT NOT NULL,
+  `wins` INT UNSIGNED NOT NULL,
+  `draws` INT UNSIGNED NOT NULL,
+  `losses` INT UNSIGNED NOT NULL,
+  PRIMARY KEY (`season`, `player`),
+  INDEX `season_rank` (`season`, `final_rank`)
+);
+
+ALTER TABLE `profiles`
+  ADD COLUMN `season_wins` INT UNSIGNED NOT NULL DEFAULT 0,
+  ADD COLUMN `season_draws` INT UNSIGNED NOT NULL DEFAULT 0,
+  ADD COLUMN `season_losses` INT UNSIGNED NOT NULL DEFAULT 0;
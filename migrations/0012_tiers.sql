@@ -0,0 +1,7 @@
+-- Each player's rank tier and division. These are derived from mmr, but are stored so that the promotion and demotion
+-- buffers can be applied. They are NULL until the player's next ranked match after the tiers were added, or after their
+-- mmr was reset, in which case the player is placed from their mmr alone.
+-- The table name should match the "db_table_profiles" environment variable.
+ALTER TABLE `profiles`
+  ADD COLUMN `tier` VARCHAR(32) NULL,
+  ADD COLUMN `division` TINYINT UNSIGNED NULL;
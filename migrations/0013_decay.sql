@@ -0,0 +1,8 @@
+-- When each player last played a ranked match, and when their rating last decayed, for the inactivity decay job. Players
+-- who have played before this migration are treated as having played now, so that nobody decays straight away.
+-- The table name should match the "db_table_profiles" environment variable.
+ALTER TABLE `profiles`
+  ADD COLUMN `last_ranked` DATETIME NULL,
+  ADD COLUMN `last_decay` DATETIME NULL;
+
+UPDATE `profiles` SET `last_ranked` = NOW() WHERE `wins` + `draws` + `losses` > 0;
@@ -0,0 +1,35 @@
+-- Every rated match between a pair of players, for detecting rating farming between the same accounts, and the review
+-- queue that suspicious pairs are flagged into for moderation.
+-- The table names should match the "db_table_pairings" and "db_table_review_queue" environment variables.
+--
+-- winner: the database ID of the winner, or 0 for a draw.
+-- duration: the length of the match in seconds, if it was submitted.
+-- multiplier: the multiplier that was applied to rating gains from the match, due to recent matches between the pair.
+CREATE TABLE IF NOT EXISTS `pairings` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `player1` BIGINT UNSIGNED NOT NULL,
+  `player2` BIGINT UNSIGNED NOT NULL,
+  `winner` BIGINT UNSIGNED NOT NULL,
+  `duration` INT UNSIGNED NULL,
+  `multiplier` DOUBLE NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`id`),
+  INDEX `players_created` (`player1`, `player2`, `created`)
+);
+
+-- player: the database ID of the flagged player.
+-- opponent: the database ID of the opponent they were flagged with.
+-- reason: why the pair was flagged, such as "repeated_losses" or "short_matches".
+-- resolved: when a moderator resolved the flag, or NULL if it is still in the queue.
+CREATE TABLE IF NOT EXISTS `review_queue` (
+  `id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+  `player` BIGINT UNSIGNED NOT NULL,
+  `opponent` BIGINT UNSIGNED NOT NULL,
+  `reason` VARCHAR(32) NOT NULL,
+  `details` VARCHAR(255) NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `resolved` DATETIME NULL,
+  `resolved_by` BIGINT UNSIGNED NULL,
+  PRIMARY KEY (`id`),
+  INDEX `resolved_created` (`resolved`, `created`)
+);
@@ -0,0 +1,20 @@
+-- The ladders for the unranked queues (such as "casual" and "event"), with a row for each player that has played a
+-- match in each queue. The ranked ladder is stored in the profiles table.
+-- The table name should match the "db_table_queue_ratings" environment variable.
+--
+-- queue: the name of the queue, as in settings.Queues.
+-- winratio: generated from the w/d/l, and null if no matches were played (as for profiles).
+CREATE TABLE IF NOT EXISTS `queue_ratings` (
+  `queue` VARCHAR(32) NOT NULL,
+  `player` BIGINT UNSIGNED NOT NULL,
+  `mmr` SMALLINT NOT NULL,
+  `rating_deviation` DOUBLE NOT NULL DEFAULT 350,
+  `rating_volatility` DOUBLE NOT NULL DEFAULT 0.06,
+  `wins` INT UNSIGNED NOT NULL DEFAULT 0,
+  `draws` INT UNSIGNED NOT NULL DEFAULT 0,
+  `losses` INT UNSIGNED NOT NULL DEFAULT 0,
+  `winratio` FLOAT AS (`wins` / (`wins` + `draws` + `losses`)),
+  `updated` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  PRIMARY KEY (`queue`, `player`),
+  INDEX `queue_mmr` (`queue`, `mmr`)
+);
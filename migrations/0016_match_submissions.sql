@@ -0,0 +1,15 @@
+-- Every processed match result submission that identified the match, either with a match ID or an Idempotency-Key
+-- header, so that retried submissions are not rated again.
+-- The table name should match the "db_table_match_submissions" environment variable.
+--
+-- key: the Idempotency-Key header prefixed with "key:", or the match ID prefixed with "match:". A submission with both
+-- is recorded once under each, so that the same match can't be rated again under a different Idempotency-Key.
+-- payload_hash: the hex encoded SHA-256 hash of the request body, for detecting a different result for the same key.
+-- response: the JSON encoded payload of the original response, returned for retries.
+CREATE TABLE IF NOT EXISTS `match_submissions` (
+  `key` VARCHAR(72) NOT NULL,
+  `payload_hash` CHAR(64) NOT NULL,
+  `response` TEXT NOT NULL,
+  `created` DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (`key`)
+);
@@ -0,0 +1,8 @@
+-- Make the canonical handle key required. This must only be run after internal/tools/backfill_handle_keys -apply has
+-- written a key for every existing account (see 0002_handle_key.sql) - otherwise it either fails, or in non-strict SQL
+-- modes sets the missing keys to '', which then collide in the unique index.
+-- The users table name should match the "db_table_users" environment variable.
+--
+-- This should return 0 before running the statement below:
+--   SELECT COUNT(*) FROM `users` WHERE `handle_key` IS NULL;
+ALTER TABLE `users` MODIFY COLUMN `handle_key` VARCHAR(80) NOT NULL;
@@ -0,0 +1,18 @@
+-- The queue that each rating change and each rated match belongs to, so that changes in the unranked queues (such as
+-- "casual" and "event") are recorded alongside ranked changes, without being mixed into the ranked timeline.
+-- The table names should match the "db_table_rating_history" and "db_table_matches" environment variables.
+--
+-- rating_history.queue: the name of the queue whose ladder changed. Every change before this migration was to the
+-- ranked ladder, as were season resets, decay and replays, so the column defaults to "ranked".
+ALTER TABLE `rating_history`
+  ADD COLUMN `queue` VARCHAR(32) NOT NULL DEFAULT 'ranked' AFTER `player`,
+  DROP INDEX `player_created`,
+  ADD INDEX `player_queue_created` (`player`, `queue`, `created`, `id`);
+
+-- matches.queue: the name of the queue the match was rated in, set when the result is submitted with its match ID, and
+-- NULL for matches whose result was not submitted with a match ID (so it is not known which queue they were rated in).
+-- Matches that already have ratings recorded could only have been rated in the ranked queue.
+ALTER TABLE `matches`
+  ADD COLUMN `queue` VARCHAR(32) NULL;
+
+UPDATE `matches` SET `queue` = 'ranked' WHERE `player1_mmr_after` IS NOT NULL;
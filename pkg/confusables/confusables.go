@@ -0,0 +1,199 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package confusables implements a skeleton function for detecting visually confusable strings, based on
+// the algorithm described in Unicode Technical Standard #39 (https://www.unicode.org/reports/tr39/#def-skeleton).
+//
+// The mappings are a deliberate, hand-picked subset of the UTS #39 confusables data rather than the full table. They
+// cover latin letters and digits, the greek and cyrillic letters that look like latin letters, dashes and horizontal
+// lines, and the japanese kana and kanji that look like katakana. Confusables from any other script, and less common
+// greek and cyrillic homoglyphs that are not in the table, are not detected.
+package confusables
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Skeleton returns the skeleton of the input string. Two strings with the same skeleton are visually confusable, such as
+// "admin" and "аdmin" (with a cyrillic "а"), "Orochi" and "0rochi", or "ロー" and "口一".
+//
+// This differs from the UTS #39 skeleton in a few ways that make it more useful for comparing handles:
+//   - NFKD is used instead of NFD, so full-width and half-width forms are folded together without needing table entries.
+//   - The result is case insensitive, as handles are unique regardless of case. As a result, "i", "I", "l" and "1" are
+//     all treated as the same character, as "I" is confusable with both "i" (by case) and "l" (visually).
+//   - Dashes and horizontal lines (including "ー" and "一") are all treated as the same character, as they are
+//     indistinguishable in the game's font.
+func Skeleton(input string) string {
+
+	// Decompose the input, so that accents and other combining marks are separated from their base characters,
+	// and compatibility characters are replaced with their standard forms.
+	skeleton := norm.NFKD.String(input)
+
+	// Map each confusable character to its prototype. This is done before lowering the case, as some upper case
+	// characters are confusable with different lower case characters (such as "I" and "l").
+	skeleton = strings.Map(prototypeRune, skeleton)
+	skeleton = replacePrototypes(skeleton)
+
+	// Lower the case, and then map again, as the lower case forms of some characters are also confusable.
+	skeleton = strings.ToLower(skeleton)
+	skeleton = replacePrototypes(strings.Map(prototypeRune, skeleton))
+
+	// Decompose again, as the prototypes themselves may not be decomposed.
+	return norm.NFKD.String(skeleton)
+}
+
+// Confusable returns true if the two strings are visually confusable with each other.
+func Confusable(a string, b string) bool {
+	return Skeleton(a) == Skeleton(b)
+}
+
+// prototypeRune returns the single rune prototype for the specified rune, or the rune itself if it has no
+// single rune prototype. Used with strings.Map.
+func prototypeRune(r rune) rune {
+	if prototype, ok := runePrototypes[r]; ok {
+		return prototype
+	}
+
+	return r
+}
+
+// replacePrototypes replaces each rune that has a multi-rune prototype (such as "m", which is confusable with "rn").
+func replacePrototypes(input string) string {
+
+	// Early exit if there is nothing to replace, which is by far the most common case.
+	if strings.IndexFunc(input, func(r rune) bool { _, ok := stringPrototypes[r]; return ok }) == -1 {
+		return input
+	}
+
+	// Rebuild the string, replacing each rune that has a multi-rune prototype.
+	var builder strings.Builder
+	for _, r := range input {
+		if prototype, ok := stringPrototypes[r]; ok {
+			builder.WriteString(prototype)
+		} else {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// stringPrototypes maps runes that are confusable with a sequence of more than one rune.
+var stringPrototypes = map[rune]string{
+	'm': "rn",
+	'w': "vv",
+}
+
+// runePrototypes maps runes to the rune that they are confusable with. This is a hand-picked subset of the mappings in
+// https://www.unicode.org/Public/security/latest/confusables.txt, covering latin, greek, cyrillic and japanese
+// characters, with the prototypes lowered where the table is applied to lower case input. New homoglyphs should be
+// added here as they are found, using the prototype from confusables.txt.
+var runePrototypes = map[rune]rune{
+
+	// Latin and digits.
+	'0': 'o',
+	'1': 'l',
+	'I': 'l',
+	'i': 'l',
+	'|': 'l',
+	'ı': 'l', // LATIN SMALL LETTER DOTLESS I
+	'ǀ': 'l', // LATIN LETTER DENTAL CLICK
+	'ɑ': 'a', // LATIN SMALL LETTER ALPHA
+	'ɡ': 'g', // LATIN SMALL LETTER SCRIPT G
+
+	// Greek.
+	'Α': 'a', // GREEK CAPITAL LETTER ALPHA
+	'Β': 'b', // GREEK CAPITAL LETTER BETA
+	'Ε': 'e', // GREEK CAPITAL LETTER EPSILON
+	'Ζ': 'z', // GREEK CAPITAL LETTER ZETA
+	'Η': 'h', // GREEK CAPITAL LETTER ETA
+	'Ι': 'l', // GREEK CAPITAL LETTER IOTA
+	'Κ': 'k', // GREEK CAPITAL LETTER KAPPA
+	'Μ': 'm', // GREEK CAPITAL LETTER MU
+	'Ν': 'n', // GREEK CAPITAL LETTER NU
+	'Ο': 'o', // GREEK CAPITAL LETTER OMICRON
+	'Ρ': 'p', // GREEK CAPITAL LETTER RHO
+	'Τ': 't', // GREEK CAPITAL LETTER TAU
+	'Υ': 'y', // GREEK CAPITAL LETTER UPSILON
+	'Χ': 'x', // GREEK CAPITAL LETTER CHI
+	'α': 'a', // GREEK SMALL LETTER ALPHA
+	'γ': 'y', // GREEK SMALL LETTER GAMMA
+	'ι': 'l', // GREEK SMALL LETTER IOTA
+	'κ': 'k', // GREEK SMALL LETTER KAPPA
+	'ν': 'v', // GREEK SMALL LETTER NU
+	'ο': 'o', // GREEK SMALL LETTER OMICRON
+	'ρ': 'p', // GREEK SMALL LETTER RHO
+	'υ': 'u', // GREEK SMALL LETTER UPSILON
+	'ϳ': 'j', // GREEK LETTER YOT
+	'Ϳ': 'j', // GREEK CAPITAL LETTER YOT
+
+	// Cyrillic.
+	'Ѕ': 's', // CYRILLIC CAPITAL LETTER DZE
+	'І': 'l', // CYRILLIC CAPITAL LETTER BYELORUSSIAN-UKRAINIAN I
+	'Ј': 'j', // CYRILLIC CAPITAL LETTER JE
+	'А': 'a', // CYRILLIC CAPITAL LETTER A
+	'В': 'b', // CYRILLIC CAPITAL LETTER VE
+	'Е': 'e', // CYRILLIC CAPITAL LETTER IE
+	'К': 'k', // CYRILLIC CAPITAL LETTER KA
+	'М': 'm', // CYRILLIC CAPITAL LETTER EM
+	'Н': 'h', // CYRILLIC CAPITAL LETTER EN
+	'О': 'o', // CYRILLIC CAPITAL LETTER O
+	'Р': 'p', // CYRILLIC CAPITAL LETTER ER
+	'С': 'c', // CYRILLIC CAPITAL LETTER ES
+	'Т': 't', // CYRILLIC CAPITAL LETTER TE
+	'У': 'y', // CYRILLIC CAPITAL LETTER U
+	'Х': 'x', // CYRILLIC CAPITAL LETTER HA
+	'а': 'a', // CYRILLIC SMALL LETTER A
+	'е': 'e', // CYRILLIC SMALL LETTER IE
+	'о': 'o', // CYRILLIC SMALL LETTER O
+	'р': 'p', // CYRILLIC SMALL LETTER ER
+	'с': 'c', // CYRILLIC SMALL LETTER ES
+	'у': 'y', // CYRILLIC SMALL LETTER U
+	'х': 'x', // CYRILLIC SMALL LETTER HA
+	'ѕ': 's', // CYRILLIC SMALL LETTER DZE
+	'і': 'l', // CYRILLIC SMALL LETTER BYELORUSSIAN-UKRAINIAN I
+	'ј': 'j', // CYRILLIC SMALL LETTER JE
+	'һ': 'h', // CYRILLIC SMALL LETTER SHHA
+	'ӏ': 'l', // CYRILLIC SMALL LETTER PALOCHKA
+	'ԁ': 'd', // CYRILLIC SMALL LETTER KOMI DE
+	'ԛ': 'q', // CYRILLIC SMALL LETTER QA
+	'ԝ': 'w', // CYRILLIC SMALL LETTER WE
+	'Ѵ': 'v', // CYRILLIC CAPITAL LETTER IZHITSA
+	'ѵ': 'v', // CYRILLIC SMALL LETTER IZHITSA
+	'Ү': 'y', // CYRILLIC CAPITAL LETTER STRAIGHT U
+	'ү': 'y', // CYRILLIC SMALL LETTER STRAIGHT U
+	'Һ': 'h', // CYRILLIC CAPITAL LETTER SHHA
+	'Ӏ': 'l', // CYRILLIC LETTER PALOCHKA
+	'Ԁ': 'd', // CYRILLIC CAPITAL LETTER KOMI DE
+	'Ԛ': 'q', // CYRILLIC CAPITAL LETTER QA
+	'Ԝ': 'w', // CYRILLIC CAPITAL LETTER WE
+
+	// Dashes and horizontal lines.
+	'‐': '-', // HYPHEN
+	'‑': '-', // NON-BREAKING HYPHEN
+	'‒': '-', // FIGURE DASH
+	'–': '-', // EN DASH
+	'—': '-', // EM DASH
+	'―': '-', // HORIZONTAL BAR
+	'−': '-', // MINUS SIGN
+	'─': '-', // BOX DRAWINGS LIGHT HORIZONTAL
+	'━': '-', // BOX DRAWINGS HEAVY HORIZONTAL
+	'ー': '-', // KATAKANA-HIRAGANA PROLONGED SOUND MARK
+	'一': '-', // CJK UNIFIED IDEOGRAPH-4E00
+	'〜': '~', // WAVE DASH
+
+	// Japanese.
+	'〇': 'o', // IDEOGRAPHIC NUMBER ZERO
+	'へ': 'ヘ', // HIRAGANA LETTER HE
+	'力': 'カ', // CJK UNIFIED IDEOGRAPH-529B
+	'口': 'ロ', // CJK UNIFIED IDEOGRAPH-53E3
+	'工': 'エ', // CJK UNIFIED IDEOGRAPH-5DE5
+	'二': 'ニ', // CJK UNIFIED IDEOGRAPH-4E8C
+	'八': 'ハ', // CJK UNIFIED IDEOGRAPH-516B
+	'卜': 'ト', // CJK UNIFIED IDEOGRAPH-535C
+	'夕': 'タ', // CJK UNIFIED IDEOGRAPH-5915
+	'・': '·', // KATAKANA MIDDLE DOT
+}
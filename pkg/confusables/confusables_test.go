@@ -0,0 +1,64 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package confusables implements a skeleton function for detecting visually confusable strings, based on
+// the algorithm described in Unicode Technical Standard #39 (https://www.unicode.org/reports/tr39/#def-skeleton).
+package confusables
+
+import (
+	"testing"
+)
+
+// Test_Confusable runs unit tests for the confusable check.
+func Test_Confusable(t *testing.T) {
+	type args struct {
+		a string
+		b string
+	}
+	tests := []struct {
+		name string
+		args args
+		want bool
+	}{
+		{name: "identical", args: args{a: "admin", b: "admin"}, want: true},
+		{name: "case", args: args{a: "Admin", b: "aDMIN"}, want: true},
+		{name: "cyrillic a", args: args{a: "admin", b: "аdmin"}, want: true},
+		{name: "cyrillic mixed", args: args{a: "Rean", b: "Rеаn"}, want: true},
+		{name: "greek omicron", args: args{a: "Orochi", b: "Οrochi"}, want: true},
+		{name: "zero for o", args: args{a: "Orochi", b: "0rochi"}, want: true},
+		{name: "one for l", args: args{a: "lily", b: "1i1y"}, want: true},
+		{name: "capital i for l", args: args{a: "lily", b: "Ilia"}, want: false},
+		{name: "capital i for l match", args: args{a: "lily", b: "IiIy"}, want: true},
+		{name: "capital i for lower case i", args: args{a: "Admin", b: "ADMIN"}, want: true},
+		{name: "rn for m", args: args{a: "moderator", b: "rnoderator"}, want: true},
+		{name: "full-width", args: args{a: "Rean", b: "Ｒｅａｎ"}, want: true},
+		{name: "prolonged sound mark vs hyphen", args: args{a: "ロー", b: "ロ-"}, want: true},
+		{name: "kanji vs katakana", args: args{a: "ロー", b: "口一"}, want: true},
+		{name: "half-width katakana", args: args{a: "カード", b: "ｶｰﾄﾞ"}, want: true},
+		{name: "hiragana he vs katakana he", args: args{a: "ヘル", b: "へル"}, want: true},
+		{name: "dakuten preserved", args: args{a: "ヘル", b: "ベル"}, want: false},
+		{name: "cyrillic straight u", args: args{a: "yuki", b: "үuki"}, want: true},
+		{name: "greek yot", args: args{a: "jam", b: "ϳam"}, want: true},
+		{name: "cyrillic capital komi de", args: args{a: "Dan", b: "Ԁan"}, want: true},
+		{name: "different", args: args{a: "admin", b: "player"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Confusable(tt.args.a, tt.args.b); got != tt.want {
+				t.Errorf("Confusable() = %v, want %v (skeletons %q and %q)", got, tt.want, Skeleton(tt.args.a), Skeleton(tt.args.b))
+			}
+		})
+	}
+}
+
+// Test_Skeleton_Idempotent ensures that the skeleton of a skeleton is itself.
+func Test_Skeleton_Idempotent(t *testing.T) {
+	inputs := []string{"admin", "Ｒｅａｎ", "ローカル", "0rochi", "Мoderator", "ｶｰﾄﾞ"}
+	for _, input := range inputs {
+		skeleton := Skeleton(input)
+		if again := Skeleton(skeleton); again != skeleton {
+			t.Errorf("Skeleton(%q) = %q, but Skeleton(%q) = %q", input, skeleton, skeleton, again)
+		}
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import "math"
+
+// predictionEpsilon keeps predictions away from 0 and 1, so that the log loss of a confident wrong prediction is large
+// rather than infinite.
+const predictionEpsilon float64 = 1e-15
+
+// Match is the result of a single match between two players, identified by ID, for replaying.
+type Match struct {
+	Player1 uint64 `json:"player1"`
+	Player2 uint64 `json:"player2"`
+	Winner  Player `json:"winner"`
+}
+
+// Backtest is the result of replaying matches through a rater. The metrics measure how well each player's rating
+// before a match predicted its result - lower log loss and Brier score are better.
+type Backtest struct {
+
+	// Ratings are the final ratings for every player, by ID.
+	Ratings map[uint64]Rating
+
+	// Matches is the number of matches that were replayed.
+	Matches int
+
+	// LogLoss is the mean log loss (cross entropy) of the predicted scores.
+	LogLoss float64
+
+	// Brier is the mean Brier score (squared error) of the predicted scores.
+	Brier float64
+
+	// Calibration groups the predictions into equal width buckets by predicted score, from both players' points of
+	// view. For well calibrated ratings, the actual score in each bucket is close to the predicted score.
+	Calibration []CalibrationBucket
+}
+
+// CalibrationBucket is the mean predicted and actual score for the predictions in a range.
+type CalibrationBucket struct {
+	Min         float64
+	Max         float64
+	Predictions int
+	Predicted   float64
+	Actual      float64
+}
+
+// Replay rates the matches in order through the rater, starting every player at the initial rating, and returns the
+// final ratings, along with metrics for how well the ratings predicted each result. The number of games for each
+// player is counted as the matches are replayed.
+func Replay(rater Rater, initial Rating, matches []Match, buckets int) (result Backtest) {
+	if buckets < 1 {
+		buckets = 1
+	}
+
+	result.Ratings = make(map[uint64]Rating)
+	result.Calibration = make([]CalibrationBucket, buckets)
+	for i := range result.Calibration {
+		result.Calibration[i].Min = float64(i) / float64(buckets)
+		result.Calibration[i].Max = float64(i+1) / float64(buckets)
+	}
+
+	for _, match := range matches {
+		player1, ok := result.Ratings[match.Player1]
+		if !ok {
+			player1 = initial
+		}
+
+		player2, ok := result.Ratings[match.Player2]
+		if !ok {
+			player2 = initial
+		}
+
+		// Score the prediction made by the ratings before the match.
+		predicted := math.Min(math.Max(rater.Expected(player1, player2), predictionEpsilon), 1-predictionEpsilon)
+		actual, _ := Scores(match.Winner)
+
+		result.LogLoss -= actual*math.Log(predicted) + (1-actual)*math.Log(1-predicted)
+		result.Brier += (predicted - actual) * (predicted - actual)
+		result.addCalibration(predicted, actual)
+		result.addCalibration(1-predicted, 1-actual)
+
+		// Rate the match, and count it for both players.
+		player1New, player2New := rater.Rate(player1, player2, match.Winner)
+		player1New.Games = player1.Games + 1
+		player2New.Games = player2.Games + 1
+		result.Ratings[match.Player1] = player1New
+		result.Ratings[match.Player2] = player2New
+		result.Matches++
+	}
+
+	// Convert the sums into means.
+	if result.Matches > 0 {
+		result.LogLoss /= float64(result.Matches)
+		result.Brier /= float64(result.Matches)
+	}
+
+	for i := range result.Calibration {
+		bucket := &result.Calibration[i]
+		if bucket.Predictions > 0 {
+			bucket.Predicted /= float64(bucket.Predictions)
+			bucket.Actual /= float64(bucket.Predictions)
+		}
+	}
+
+	return result
+}
+
+// addCalibration adds a prediction, and the actual score, to the bucket that the prediction falls into.
+func (b *Backtest) addCalibration(predicted float64, actual float64) {
+	i := int(predicted * float64(len(b.Calibration)))
+	if i >= len(b.Calibration) {
+		i = len(b.Calibration) - 1
+	}
+
+	b.Calibration[i].Predictions++
+	b.Calibration[i].Predicted += predicted
+	b.Calibration[i].Actual += actual
+}
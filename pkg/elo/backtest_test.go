@@ -0,0 +1,81 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"math"
+	"testing"
+)
+
+// Test_Replay runs unit tests for replaying matches and scoring the predictions.
+func Test_Replay(t *testing.T) {
+	initial := Rating{Value: 1200}
+	matches := []Match{
+		{Player1: 1, Player2: 2, Winner: Player1},
+		{Player1: 2, Player2: 3, Winner: Draw},
+	}
+
+	got := Replay(NewCalculator(), initial, matches, 10)
+
+	// The first match is between equal players, and the second is between a player who lost 16 points and a new player.
+	wantRatings := map[uint64]Rating{
+		1: {Value: 1216, Games: 1},
+		2: {Value: 1185, Games: 2},
+		3: {Value: 1199, Games: 1},
+	}
+	for id, want := range wantRatings {
+		if got.Ratings[id] != want {
+			t.Errorf("Replay() rating for %v = %v, want %v", id, got.Ratings[id], want)
+		}
+	}
+
+	// Both predictions are close to even, so the log loss is about ln(2) for both the win and the draw. The Brier score
+	// is 0.25 for the win, and close to 0 for the draw.
+	if got.Matches != 2 {
+		t.Errorf("Replay() matches = %v, want 2", got.Matches)
+	}
+	if math.Abs(got.LogLoss-math.Ln2) > 0.001 {
+		t.Errorf("Replay() log loss = %v, want about %v", got.LogLoss, math.Ln2)
+	}
+	if math.Abs(got.Brier-0.125) > 0.001 {
+		t.Errorf("Replay() Brier score = %v, want about 0.125", got.Brier)
+	}
+
+	// Every prediction is counted from both players' points of view.
+	var predictions int
+	for _, bucket := range got.Calibration {
+		predictions += bucket.Predictions
+	}
+	if predictions != 4 {
+		t.Errorf("Replay() calibration predictions = %v, want 4", predictions)
+	}
+	if bucket := got.Calibration[4]; bucket.Predictions != 1 || bucket.Actual != 0.5 {
+		t.Errorf("Replay() calibration bucket 4 = %+v, want the underdog's prediction for the draw", bucket)
+	}
+}
+
+// Test_Expected runs unit tests for the expected score of each rater.
+func Test_Expected(t *testing.T) {
+	tests := []struct {
+		name     string
+		rater    Rater
+		player   Rating
+		opponent Rating
+		want     float64
+	}{
+		{name: "elo equal", rater: NewCalculator(), player: Rating{Value: 1500}, opponent: Rating{Value: 1500}, want: 0.5},
+		{name: "elo 10x better", rater: NewCalculator(), player: Rating{Value: 1600}, opponent: Rating{Value: 1200}, want: 10.0 / 11},
+		{name: "glicko2 equal", rater: Glicko2{}, player: Rating{Value: 1500, Deviation: 50}, opponent: Rating{Value: 1500, Deviation: 300}, want: 0.5},
+		{name: "glicko2 uncertain", rater: Glicko2{}, player: Rating{Value: 1600, Deviation: DefaultDeviation}, opponent: Rating{Value: 1200, Deviation: DefaultDeviation}, want: 0.775},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rater.Expected(tt.player, tt.opponent); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("Expected() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
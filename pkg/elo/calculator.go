@@ -0,0 +1,172 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import "math"
+
+// Default values for calculators.
+const (
+
+	// DefaultK is the default maximum elo shift after a game.
+	DefaultK int = 32
+
+	// DefaultTenXMod is the default number of elo points required to be considered 10x better/worse than someone.
+	DefaultTenXMod float64 = 400.0
+)
+
+// Calculator is a Rater for the elo rating system. Changes in rating are rounded to whole points, so that whole ratings
+// stay whole. Deviation and volatility are not used, and are returned unchanged.
+//
+// Calculators are immutable once created, so a single calculator can be used by multiple goroutines, and calculators
+// with different parameters (such as for different queues) can be used side by side.
+type Calculator struct {
+
+	// k is basically the maximum elo shift after a game, used for every player if there is no k policy.
+	k int
+
+	// tenXMod is the number of elo points required to be considered 10x better/worse than someone.
+	tenXMod float64
+
+	// kPolicy decides the k value for each player, if not nil.
+	kPolicy *KPolicy
+}
+
+// Option is an option for a Calculator.
+type Option func(c *Calculator)
+
+// WithK sets the k value used for every player.
+func WithK(k int) Option {
+	return func(c *Calculator) {
+		c.k = k
+	}
+}
+
+// WithTenXMod sets the number of elo points required to be considered 10x better/worse than someone.
+func WithTenXMod(tenXMod float64) Option {
+	return func(c *Calculator) {
+		c.tenXMod = tenXMod
+	}
+}
+
+// WithKPolicy sets the policy that decides the k value for each player, replacing the single k value. The policy is
+// copied, so changing it afterwards does not affect the calculator.
+func WithKPolicy(policy KPolicy) Option {
+	return func(c *Calculator) {
+		policy.Bands = append([]KBand(nil), policy.Bands...)
+		c.kPolicy = &policy
+	}
+}
+
+// NewCalculator creates a calculator with the default parameters, changed by the specified options.
+func NewCalculator(options ...Option) Calculator {
+	c := Calculator{
+		k:       DefaultK,
+		tenXMod: DefaultTenXMod,
+	}
+
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c
+}
+
+// With returns a copy of the calculator, changed by the specified options.
+func (c Calculator) With(options ...Option) Calculator {
+	for _, option := range options {
+		option(&c)
+	}
+
+	return c
+}
+
+// CalculateNewElo calculates the new elo ratings for both players depending on the outcome of a match.
+func (c Calculator) CalculateNewElo(player1Elo int16, player2Elo int16, winner Player) (player1NewElo int16, player2NewElo int16) {
+	player1New, player2New := c.Rate(Rating{Value: float64(player1Elo)}, Rating{Value: float64(player2Elo)}, winner)
+
+	// return both new elo values.
+	return int16(player1New.Value), int16(player2New.Value)
+}
+
+// Rate returns the new ratings for both players after a single match between them. If both players have the same k
+// value, the changes in their ratings sum to zero.
+func (c Calculator) Rate(player1 Rating, player2 Rating, winner Player) (player1New Rating, player2New Rating) {
+
+	// Get the win chance and score for each player.
+	player1WinChance := c.expectedScore(player1.Value, player2.Value)
+	player1Score, player2Score := Scores(winner)
+
+	player1New, player2New = player1, player2
+	player1K, player2K := c.kFor(player1), c.kFor(player2)
+
+	// If both players have the same k value, player 2's elo shift is the opposite of player 1's, so that no rating
+	// is created or lost due to rounding.
+	if player1K == player2K {
+		eloShift := math.Round(float64(player1K) * (player1Score - player1WinChance))
+		player1New.Value += eloShift
+		player2New.Value -= eloShift
+
+		return player1New, player2New
+	}
+
+	// Otherwise, determine the new elo for each player based on their own k value.
+	player1New.Value += math.Round(float64(player1K) * (player1Score - player1WinChance))
+	player2New.Value += math.Round(float64(player2K) * (player2Score - (1 - player1WinChance)))
+
+	return player1New, player2New
+}
+
+// RatePeriod returns a player's new rating after a rating period in which they played the specified matches. The
+// changes for each match are summed, and then rounded.
+func (c Calculator) RatePeriod(player Rating, results []Result) Rating {
+	var change float64
+	for _, result := range results {
+		change += float64(c.kFor(player)) * (result.Score - c.expectedScore(player.Value, result.Opponent.Value))
+	}
+
+	player.Value += math.Round(change)
+
+	return player
+}
+
+// Expected returns the expected score for a player in a match against an opponent, based on their ratings.
+func (c Calculator) Expected(player Rating, opponent Rating) float64 {
+	return c.expectedScore(player.Value, opponent.Value)
+}
+
+// kFor returns the k value for the specified player.
+func (c Calculator) kFor(player Rating) int {
+	if c.kPolicy == nil {
+		return c.k
+	}
+
+	return c.kPolicy.KFor(player)
+}
+
+// WinChance determines the chance for each player to win, based on the elo of both players. A draw is counted as half
+// a win, so the chances always sum to 1.
+func (c Calculator) WinChance(player1Elo int16, player2Elo int16) (player1Chance float64, player2Chance float64) {
+
+	// Determine the win chance for player 1.
+	player1Chance = c.expectedScore(float64(player1Elo), float64(player2Elo))
+
+	// Determine the win chance for player 2.
+	player2Chance = 1 - player1Chance
+
+	// Return both win chances.
+	return player1Chance, player2Chance
+}
+
+// expectedScore returns the expected score for a player against an opponent, based on their ratings. This is the chance
+// of the player winning, with a draw counted as half a win.
+func (c Calculator) expectedScore(rating float64, opponentRating float64) float64 {
+
+	// Divide the difference by the elo difference required to be considered 10 times better than another
+	// player.
+	diffOverTenX := (opponentRating - rating) / c.tenXMod
+
+	return 1.0 / (1 + math.Pow(10, diffOverTenX))
+}
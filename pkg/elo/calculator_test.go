@@ -0,0 +1,128 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"sync"
+	"testing"
+	"testing/quick"
+)
+
+// Test_Calculator_RatePeriod runs unit tests for elo rating periods.
+func Test_Calculator_RatePeriod(t *testing.T) {
+	player := Rating{Value: 2000}
+	results := []Result{
+		{Opponent: Rating{Value: 2000}, Score: winScore},
+		{Opponent: Rating{Value: 2400}, Score: drawScore},
+	}
+
+	// 16 for the win, and around 13.1 for the draw.
+	if got := NewCalculator().RatePeriod(player, results); got.Value != 2029 {
+		t.Errorf("RatePeriod() value = %v, want %v", got.Value, 2029)
+	}
+}
+
+// Test_Calculator_Options ensures that options only affect the calculator they are applied to.
+func Test_Calculator_Options(t *testing.T) {
+	base := NewCalculator()
+	halfK := base.With(WithK(16))
+
+	if player1, _ := base.CalculateNewElo(2000, 2000, Player1); player1 != 2016 {
+		t.Errorf("CalculateNewElo() base = %v, want %v", player1, 2016)
+	}
+	if player1, _ := halfK.CalculateNewElo(2000, 2000, Player1); player1 != 2008 {
+		t.Errorf("CalculateNewElo() half k = %v, want %v", player1, 2008)
+	}
+
+	// Changing a policy after it has been used to create a calculator should not affect the calculator.
+	policy := KPolicy{K: 32, Bands: []KBand{{MinRating: 0, K: 16}}}
+	withPolicy := NewCalculator(WithKPolicy(policy))
+	policy.Bands[0].K = 64
+
+	if player1, _ := withPolicy.CalculateNewElo(2000, 2000, Player1); player1 != 2008 {
+		t.Errorf("CalculateNewElo() with policy = %v, want %v", player1, 2008)
+	}
+}
+
+// Test_Calculator_ZeroSum checks that rating changes always sum to zero when both players have the same k value.
+func Test_Calculator_ZeroSum(t *testing.T) {
+	calculator := NewCalculator()
+	property := func(player1Elo int16, player2Elo int16, winner uint8) bool {
+		player1Elo, player2Elo = player1Elo/4, player2Elo/4
+		player1NewElo, player2NewElo := calculator.CalculateNewElo(player1Elo, player2Elo, Player(winner%3))
+
+		return int(player1NewElo)+int(player2NewElo) == int(player1Elo)+int(player2Elo)
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// Test_Calculator_EqualDraw checks that a draw between players with the same rating never changes their ratings.
+func Test_Calculator_EqualDraw(t *testing.T) {
+	calculator := NewCalculator()
+	property := func(elo int16) bool {
+		player1NewElo, player2NewElo := calculator.CalculateNewElo(elo, elo, Draw)
+
+		return player1NewElo == elo && player2NewElo == elo
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// Test_DefaultCalculator_Concurrent changes the default calculator while it is being used, and should be run with the
+// race detector.
+func Test_DefaultCalculator_Concurrent(t *testing.T) {
+	defer SetK(DefaultK)
+	defer SetNewTenXMod(DefaultTenXMod)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			SetK(DefaultK)
+			SetNewTenXMod(DefaultTenXMod)
+		}()
+		go func() {
+			defer wg.Done()
+			CalculateNewElo(2000, 2000, Player1)
+		}()
+	}
+
+	wg.Wait()
+}
+
+// FuzzCalculator_Rate checks the same properties as the tests above, for any ratings, outcome and k value.
+func FuzzCalculator_Rate(f *testing.F) {
+	f.Add(2000.0, 2000.0, uint8(Player1), 32)
+	f.Add(2000.0, 2400.0, uint8(Draw), 32)
+	f.Add(-500.0, 3000.0, uint8(Player2), 1)
+
+	f.Fuzz(func(t *testing.T, player1Value float64, player2Value float64, winner uint8, k int) {
+
+		// Keep the inputs within a range where the rounded results are exact.
+		if player1Value < -1e6 || player1Value > 1e6 || player2Value < -1e6 || player2Value > 1e6 || k < 0 || k > 1000 {
+			t.Skip()
+		}
+
+		calculator := NewCalculator(WithK(k))
+		player1 := Rating{Value: float64(int64(player1Value))}
+		player2 := Rating{Value: float64(int64(player2Value))}
+
+		player1New, player2New := calculator.Rate(player1, player2, Player(winner%3))
+		if change := (player1New.Value - player1.Value) + (player2New.Value - player2.Value); change != 0 {
+			t.Errorf("Rate() changes sum to %v, want 0", change)
+		}
+
+		if player1New, player2New = calculator.Rate(player1, player1, Draw); player1New != player1 || player2New != player1 {
+			t.Errorf("Rate() equal draw = %v, %v, want %v", player1New, player2New, player1)
+		}
+	})
+}
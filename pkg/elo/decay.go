@@ -0,0 +1,68 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"math"
+	"time"
+)
+
+// DecayPolicy decides how the ratings of inactive players decay, based on their tier, so that players can't hold a
+// high rating forever without playing.
+type DecayPolicy struct {
+
+	// Rules are the decay rules for each tier, by tier name. The ratings of players in tiers without a rule never decay.
+	Rules map[string]DecayRule
+}
+
+// DecayRule is how the rating of an inactive player in a tier decays, each time decay is applied.
+type DecayRule struct {
+
+	// GracePeriod is how long a player can go without playing before their rating starts to decay.
+	GracePeriod time.Duration
+
+	// Points is how much the player's rating is lowered, down to Floor.
+	Points float64
+
+	// Floor is the rating below which decay won't lower the player's rating.
+	Floor float64
+
+	// Deviation is how much the player's rating deviation is raised, up to DefaultDeviation.
+	Deviation float64
+}
+
+// Decay returns the decayed rating for a player in the specified tier who has not played for the specified duration,
+// and true if the rating changed.
+func (p *DecayPolicy) Decay(player Rating, tier string, inactive time.Duration) (Rating, bool) {
+	rule, ok := p.Rules[tier]
+	if !ok || inactive < rule.GracePeriod {
+		return player, false
+	}
+
+	decayed := player
+	if decayed.Value > rule.Floor {
+		decayed.Value = math.Max(decayed.Value-rule.Points, rule.Floor)
+	}
+
+	if decayed.Deviation < DefaultDeviation {
+		decayed.Deviation = math.Min(decayed.Deviation+rule.Deviation, DefaultDeviation)
+	}
+
+	return decayed, decayed != player
+}
+
+// MinGracePeriod returns the shortest grace period of any rule, which is how long a player must be inactive before
+// their rating can decay at all, and false if there are no rules.
+func (p *DecayPolicy) MinGracePeriod() (min time.Duration, ok bool) {
+	for _, rule := range p.Rules {
+		if !ok || rule.GracePeriod < min {
+			min = rule.GracePeriod
+			ok = true
+		}
+	}
+
+	return min, ok
+}
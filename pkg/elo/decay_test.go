@@ -0,0 +1,50 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"testing"
+	"time"
+)
+
+// Test_DecayPolicy_Decay runs unit tests for decaying the ratings of inactive players.
+func Test_DecayPolicy_Decay(t *testing.T) {
+	day := 24 * time.Hour
+	policy := &DecayPolicy{
+		Rules: map[string]DecayRule{
+			"Gold":   {GracePeriod: 28 * day, Deviation: 10},
+			"Master": {GracePeriod: 14 * day, Points: 15, Floor: 1800, Deviation: 10},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		player      Rating
+		tier        string
+		inactive    time.Duration
+		want        Rating
+		wantDecayed bool
+	}{
+		{name: "no rule", player: Rating{Value: 1000, Deviation: 50}, tier: "Bronze", inactive: 100 * day, want: Rating{Value: 1000, Deviation: 50}},
+		{name: "within the grace period", player: Rating{Value: 2000, Deviation: 50}, tier: "Master", inactive: 13 * day, want: Rating{Value: 2000, Deviation: 50}},
+		{name: "deviation only", player: Rating{Value: 1300, Deviation: 50}, tier: "Gold", inactive: 28 * day, want: Rating{Value: 1300, Deviation: 60}, wantDecayed: true},
+		{name: "rating and deviation", player: Rating{Value: 2000, Deviation: 50}, tier: "Master", inactive: 14 * day, want: Rating{Value: 1985, Deviation: 60}, wantDecayed: true},
+		{name: "down to the floor", player: Rating{Value: 1805, Deviation: 345}, tier: "Master", inactive: 20 * day, want: Rating{Value: 1800, Deviation: DefaultDeviation}, wantDecayed: true},
+		{name: "at the floor and maximum deviation", player: Rating{Value: 1800, Deviation: DefaultDeviation}, tier: "Master", inactive: 20 * day, want: Rating{Value: 1800, Deviation: DefaultDeviation}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, decayed := policy.Decay(tt.player, tt.tier, tt.inactive)
+			if got != tt.want || decayed != tt.wantDecayed {
+				t.Errorf("Decay() = %v, %v, want %v, %v", got, decayed, tt.want, tt.wantDecayed)
+			}
+		})
+	}
+
+	if min, ok := policy.MinGracePeriod(); !ok || min != 14*day {
+		t.Errorf("MinGracePeriod() = %v, %v, want %v, true", min, ok, 14*day)
+	}
+}
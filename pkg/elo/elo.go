@@ -5,18 +5,22 @@
 // Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
 package elo
 
-import "math"
+import (
+	"sync"
+	"sync/atomic"
+)
 
 var (
 
 	// Default is the default starting elo for all new users.
 	Default int = 1200
 
-	// This value is the number of elo points required to be considered 10x better/worse than someone
-	tenXMod float64 = 400.0
+	// defaultCalculator is the Calculator used by the package level functions, stored as an atomic value so that it
+	// can be replaced by SetK and SetNewTenXMod while other goroutines are using it.
+	defaultCalculator atomic.Value
 
-	// Not sure exactly what k is, but its basically the maximum elo shift after a game
-	k int = 32
+	// defaultCalculatorLock serializes changes to the default calculator, so that concurrent changes are not lost.
+	defaultCalculatorLock sync.Mutex
 )
 
 // const values for elo calculations.
@@ -24,60 +28,44 @@ const lossScore float64 = 0.0
 const drawScore float64 = 0.5
 const winScore float64 = 1.0
 
-// CalculateNewElo calculates the new elo ratings for both players depending on the outcome of a match.
-func CalculateNewElo(player1Elo int16, player2Elo int16, winner Player) (player1NewElo int16, player2NewElo int16) {
-
-	// Get the win chance for player 1. We dont need the value for player 2 because player 2's new elo is
-	// determined by the values for player 1 instead.
-	player1WinChance, _ := getWinChance(player1Elo, player2Elo)
-
-	// Determine the score for player 1 (win draw or loss values).
-	var player1Score float64
-	if winner == Player1 {
-		player1Score = winScore
-	} else if winner == Draw {
-		player1Score = drawScore
-	} else {
-		player1Score = lossScore
-	}
-
-	// Determine the elo shift for both players.
-	eloShift := int16(math.Round(float64(k) * (player1Score - player1WinChance)))
-
-	// Determine the new elo for each player.
-	player1NewElo = player1Elo + eloShift
-	player2NewElo = player2Elo - eloShift
-
-	// return both new elo values.
-	return player1NewElo, player2NewElo
+func init() {
+	defaultCalculator.Store(NewCalculator())
 }
 
-// SetK sets a new internal value for K.
-func SetK(newK int) {
-	k = newK
+// DefaultCalculator returns the Calculator used by the package level functions.
+func DefaultCalculator() Calculator {
+	return defaultCalculator.Load().(Calculator)
 }
 
-// SetNewTenXMod sets a new internal value for the 10x mod (number of points required to be 10x better/worse than someone.
-func SetNewTenXMod(newMod float64) {
-	tenXMod = newMod
+// CalculateNewElo calculates the new elo ratings for both players depending on the outcome of a match, using the
+// default calculator.
+func CalculateNewElo(player1Elo int16, player2Elo int16, winner Player) (player1NewElo int16, player2NewElo int16) {
+	return DefaultCalculator().CalculateNewElo(player1Elo, player2Elo, winner)
 }
 
-// getWinChance determines the chance for each player to win, based on the elo of both players.
-func getWinChance(player1Elo int16, player2Elo int16) (player1Chance float64, player2Chance float64) {
-
-	// Determine the difference in elo.
-	diff := player2Elo - player1Elo
+// SetK sets a new value for K in the default calculator.
+//
+// Deprecated: Create a Calculator with the WithK option instead, as this affects every user of the package.
+func SetK(newK int) {
+	defaultCalculatorLock.Lock()
+	defer defaultCalculatorLock.Unlock()
 
-	// Divide the difference by the elo difference required to be considered 10 times better than another
-	// player.
-	diffOverTenX := float64(diff) / tenXMod
+	defaultCalculator.Store(DefaultCalculator().With(WithK(newK)))
+}
 
-	// Determine the win chance for player 1.
-	player1Chance = 1.0 / (1 + math.Pow(10, diffOverTenX))
+// SetNewTenXMod sets a new value for the 10x mod (number of points required to be 10x better/worse than someone) in
+// the default calculator.
+//
+// Deprecated: Create a Calculator with the WithTenXMod option instead, as this affects every user of the package.
+func SetNewTenXMod(newMod float64) {
+	defaultCalculatorLock.Lock()
+	defer defaultCalculatorLock.Unlock()
 
-	// Determine the win chance for player 2.
-	player2Chance = 1 - player1Chance
+	defaultCalculator.Store(DefaultCalculator().With(WithTenXMod(newMod)))
+}
 
-	// Return both win chances.
-	return player1Chance, player2Chance
+// WinChance determines the chance for each player to win, based on the elo of both players, using the default
+// calculator. A draw is counted as half a win, so the chances always sum to 1.
+func WinChance(player1Elo int16, player2Elo int16) (player1Chance float64, player2Chance float64) {
+	return DefaultCalculator().WinChance(player1Elo, player2Elo)
 }
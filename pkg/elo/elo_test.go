@@ -106,8 +106,8 @@ func Test_CalculateNewElo(t *testing.T) {
 	}
 }
 
-// Test_getWinChange runs unit tests for the win chance calculator.
-func Test_getWinChance(t *testing.T) {
+// Test_WinChance runs unit tests for the win chance calculator.
+func Test_WinChance(t *testing.T) {
 	type args struct {
 		player1Elo int16
 		player2Elo int16
@@ -148,12 +148,12 @@ func Test_getWinChance(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotPlayer1Chance, gotPlayer2Chance := getWinChance(tt.args.player1Elo, tt.args.player2Elo)
+			gotPlayer1Chance, gotPlayer2Chance := WinChance(tt.args.player1Elo, tt.args.player2Elo)
 			if gotPlayer1Chance != tt.wantPlayer1Chance {
-				t.Errorf("getWinChance() gotPlayer1Chance = %v, want %v", gotPlayer1Chance, tt.wantPlayer1Chance)
+				t.Errorf("WinChance() gotPlayer1Chance = %v, want %v", gotPlayer1Chance, tt.wantPlayer1Chance)
 			}
 			if gotPlayer2Chance != tt.wantPlayer2Chance {
-				t.Errorf("getWinChance() gotPlayer2Chance = %v, want %v", gotPlayer2Chance, tt.wantPlayer2Chance)
+				t.Errorf("WinChance() gotPlayer2Chance = %v, want %v", gotPlayer2Chance, tt.wantPlayer2Chance)
 			}
 		})
 	}
@@ -0,0 +1,161 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import "math"
+
+// Default values for Glicko-2 ratings and the Glicko-2 rating system.
+const (
+
+	// DefaultDeviation is the rating deviation for a new player, which is also the highest that a deviation can be.
+	DefaultDeviation float64 = 350
+
+	// DefaultVolatility is the volatility for a new player.
+	DefaultVolatility float64 = 0.06
+
+	// DefaultTau is the default system constant, which constrains the change in volatility over time.
+	DefaultTau float64 = 0.5
+)
+
+const (
+
+	// glicko2Scale converts ratings and deviations between the Glicko scale and the Glicko-2 scale.
+	glicko2Scale float64 = 173.7178
+
+	// glicko2Center is the rating that is zero on the Glicko-2 scale. Only the difference between ratings matters, so
+	// this has no effect on the results.
+	glicko2Center float64 = 1500
+
+	// glicko2Convergence is the tolerance to which the new volatility is calculated.
+	glicko2Convergence float64 = 0.000001
+)
+
+// Glicko2 is a Rater for the Glicko-2 rating system, as described in http://www.glicko.net/glicko/glicko2.pdf.
+// Ratings move quickly while their deviation is high (such as for new players), and slowly once it is low.
+type Glicko2 struct {
+
+	// Tau is the system constant, which constrains the change in volatility over time. Reasonable values are between
+	// 0.3 and 1.2, with smaller values preventing large changes in rating after improbable results. Defaults to
+	// DefaultTau if zero.
+	Tau float64
+}
+
+// Rate returns the new ratings for both players after a single match between them, treating the match as a rating
+// period for each player.
+func (g Glicko2) Rate(player1 Rating, player2 Rating, winner Player) (player1New Rating, player2New Rating) {
+	player1Score, player2Score := Scores(winner)
+
+	player1New = g.RatePeriod(player1, []Result{{Opponent: player2, Score: player1Score}})
+	player2New = g.RatePeriod(player2, []Result{{Opponent: player1, Score: player2Score}})
+
+	return player1New, player2New
+}
+
+// RatePeriod returns a player's new rating after a rating period in which they played the specified matches. If they
+// did not play, only their deviation increases, up to DefaultDeviation.
+func (g Glicko2) RatePeriod(player Rating, results []Result) Rating {
+
+	// Convert the rating and deviation to the Glicko-2 scale.
+	mu := (player.Value - glicko2Center) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+	sigma := player.Volatility
+
+	// A player that did not play only becomes less certain.
+	if len(results) == 0 {
+		player.Deviation = math.Min(math.Sqrt(phi*phi+sigma*sigma)*glicko2Scale, DefaultDeviation)
+		return player
+	}
+
+	// Compute the estimated variance of the player's rating based only on the results (v), and the estimated
+	// improvement in rating (delta, which is v multiplied by the sum of the improvements).
+	var variance, improvement float64
+	for _, result := range results {
+		opponentMu := (result.Opponent.Value - glicko2Center) / glicko2Scale
+		opponentG := glicko2G(result.Opponent.Deviation / glicko2Scale)
+		expected := 1 / (1 + math.Exp(-opponentG*(mu-opponentMu)))
+
+		variance += opponentG * opponentG * expected * (1 - expected)
+		improvement += opponentG * (result.Score - expected)
+	}
+
+	variance = 1 / variance
+	delta := variance * improvement
+
+	// Determine the new volatility, and then the new deviation and rating.
+	newSigma := g.volatility(phi, sigma, variance, delta)
+	phiStar := math.Sqrt(phi*phi + newSigma*newSigma)
+	newPhi := 1 / math.Sqrt(1/(phiStar*phiStar)+1/variance)
+	newMu := mu + newPhi*newPhi*improvement
+
+	// Convert back to the Glicko scale.
+	return Rating{
+		Value:      newMu*glicko2Scale + glicko2Center,
+		Deviation:  math.Min(newPhi*glicko2Scale, DefaultDeviation),
+		Volatility: newSigma,
+	}
+}
+
+// Expected returns the expected score for a player in a match against an opponent. Unlike the expected score used
+// while rating, this accounts for the deviation of both ratings, as recommended for predicting results.
+func (g Glicko2) Expected(player Rating, opponent Rating) float64 {
+	mu := (player.Value - glicko2Center) / glicko2Scale
+	opponentMu := (opponent.Value - glicko2Center) / glicko2Scale
+	phi := math.Hypot(player.Deviation, opponent.Deviation) / glicko2Scale
+
+	return 1 / (1 + math.Exp(-glicko2G(phi)*(mu-opponentMu)))
+}
+
+// volatility returns the new volatility, found iteratively using the Illinois algorithm (step 5 of the paper).
+func (g Glicko2) volatility(phi float64, sigma float64, variance float64, delta float64) float64 {
+	tau := g.Tau
+	if tau == 0 {
+		tau = DefaultTau
+	}
+
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + variance + ex
+
+		return ex*(delta*delta-phi*phi-variance-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	// Bracket the solution between A and B.
+	A := a
+	var B float64
+	if delta*delta > phi*phi+variance {
+		B = math.Log(delta*delta - phi*phi - variance)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+
+		B = a - k*tau
+	}
+
+	// Narrow the bracket until it is within the convergence tolerance.
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > glicko2Convergence {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+// glicko2G reduces the impact of a result based on the opponent's deviation (on the Glicko-2 scale), as a result
+// against an opponent whose rating is uncertain says less about the player's rating.
+func glicko2G(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
@@ -0,0 +1,79 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"math"
+	"testing"
+)
+
+// Test_Glicko2_RatePeriod runs unit tests for Glicko-2 rating periods, including the example from the Glicko-2 paper.
+func Test_Glicko2_RatePeriod(t *testing.T) {
+	tests := []struct {
+		name    string
+		player  Rating
+		results []Result
+		want    Rating
+	}{
+		{
+			name:   "published example",
+			player: Rating{Value: 1500, Deviation: 200, Volatility: 0.06},
+			results: []Result{
+				{Opponent: Rating{Value: 1400, Deviation: 30}, Score: winScore},
+				{Opponent: Rating{Value: 1550, Deviation: 100}, Score: lossScore},
+				{Opponent: Rating{Value: 1700, Deviation: 300}, Score: lossScore},
+			},
+			want: Rating{Value: 1464.06, Deviation: 151.52, Volatility: 0.05999},
+		},
+		{
+			name:    "no matches",
+			player:  Rating{Value: 1500, Deviation: 200, Volatility: 0.06},
+			results: []Result{},
+			want:    Rating{Value: 1500, Deviation: 200.27, Volatility: 0.06},
+		},
+		{
+			name:    "no matches at the maximum deviation",
+			player:  Rating{Value: 1500, Deviation: DefaultDeviation, Volatility: 0.06},
+			results: []Result{},
+			want:    Rating{Value: 1500, Deviation: DefaultDeviation, Volatility: 0.06},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Glicko2{Tau: 0.5}.RatePeriod(tt.player, tt.results)
+			if math.Abs(got.Value-tt.want.Value) > 0.01 {
+				t.Errorf("RatePeriod() value = %v, want %v", got.Value, tt.want.Value)
+			}
+			if math.Abs(got.Deviation-tt.want.Deviation) > 0.01 {
+				t.Errorf("RatePeriod() deviation = %v, want %v", got.Deviation, tt.want.Deviation)
+			}
+			if math.Abs(got.Volatility-tt.want.Volatility) > 0.00001 {
+				t.Errorf("RatePeriod() volatility = %v, want %v", got.Volatility, tt.want.Volatility)
+			}
+		})
+	}
+}
+
+// Test_Glicko2_Rate runs unit tests for rating single matches with Glicko-2.
+func Test_Glicko2_Rate(t *testing.T) {
+	newPlayer := Rating{Value: 1500, Deviation: DefaultDeviation, Volatility: DefaultVolatility}
+	veteran := Rating{Value: 1500, Deviation: 50, Volatility: DefaultVolatility}
+
+	// Equal players that draw should not move, and both should become more certain.
+	player1, player2 := Glicko2{}.Rate(newPlayer, newPlayer, Draw)
+	if player1.Value != newPlayer.Value || player2.Value != newPlayer.Value {
+		t.Errorf("Rate() draw values = %v, %v, want %v", player1.Value, player2.Value, newPlayer.Value)
+	}
+	if player1.Deviation >= newPlayer.Deviation || player2.Deviation >= newPlayer.Deviation {
+		t.Errorf("Rate() draw deviations = %v, %v, want less than %v", player1.Deviation, player2.Deviation, newPlayer.Deviation)
+	}
+
+	// A new player should move much further than a veteran with the same rating.
+	player1, player2 = Glicko2{}.Rate(newPlayer, veteran, Player1)
+	if gain, loss := player1.Value-newPlayer.Value, veteran.Value-player2.Value; gain <= loss*4 || loss <= 0 {
+		t.Errorf("Rate() new player gained %v, veteran lost %v, want the gain to be much larger", gain, loss)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+// KPolicy decides the k value (the maximum elo shift after a game) for each player, so that a player's rating moves
+// quickly while it is still being established, and slowly once they are highly rated.
+type KPolicy struct {
+
+	// PlacementGames is the number of games a player must play before their rating is no longer provisional.
+	PlacementGames uint32
+
+	// ProvisionalK is the k value for players whose rating is provisional.
+	ProvisionalK int
+
+	// K is the k value for players whose rating is established, and below every band.
+	K int
+
+	// Bands are the k values for established players at or above each rating, in ascending order of rating.
+	Bands []KBand
+}
+
+// KBand is the k value for established players at or above a rating.
+type KBand struct {
+	MinRating float64
+	K         int
+}
+
+// Provisional returns true if a player who has played the specified number of games still has a provisional rating.
+func (p *KPolicy) Provisional(games uint32) bool {
+	return games < p.PlacementGames
+}
+
+// KFor returns the k value for the specified player.
+func (p *KPolicy) KFor(player Rating) int {
+	if p.Provisional(player.Games) {
+		return p.ProvisionalK
+	}
+
+	k := p.K
+	for _, band := range p.Bands {
+		if player.Value < band.MinRating {
+			break
+		}
+
+		k = band.K
+	}
+
+	return k
+}
@@ -0,0 +1,54 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"testing"
+)
+
+// Test_KPolicy_KFor runs unit tests for choosing k values.
+func Test_KPolicy_KFor(t *testing.T) {
+	policy := &KPolicy{
+		PlacementGames: 10,
+		ProvisionalK:   40,
+		K:              32,
+		Bands: []KBand{
+			{MinRating: 2000, K: 24},
+			{MinRating: 2400, K: 16},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		player Rating
+		want   int
+	}{
+		{name: "provisional", player: Rating{Value: 2500, Games: 9}, want: 40},
+		{name: "established", player: Rating{Value: 1200, Games: 10}, want: 32},
+		{name: "first band", player: Rating{Value: 2000, Games: 10}, want: 24},
+		{name: "second band", player: Rating{Value: 2500, Games: 100}, want: 16},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.KFor(tt.player); got != tt.want {
+				t.Errorf("KFor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_Calculator_Rate_KPolicy ensures that each player's rating moves by their own k value.
+func Test_Calculator_Rate_KPolicy(t *testing.T) {
+	policy := &KPolicy{PlacementGames: 10, ProvisionalK: 40, K: 20}
+
+	newPlayer := Rating{Value: 2000, Games: 0}
+	veteran := Rating{Value: 2000, Games: 50}
+
+	player1, player2 := NewCalculator(WithKPolicy(*policy)).Rate(newPlayer, veteran, Player1)
+	if player1.Value != 2020 || player2.Value != 1990 {
+		t.Errorf("Rate() = %v, %v, want %v, %v", player1.Value, player2.Value, 2020, 1990)
+	}
+}
@@ -0,0 +1,14 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import "math"
+
+// MatchQuality returns how fair a match between two players would be, from 0 (one player is certain to win) to 1 (both
+// players are equally likely to win), based on the expected score from the specified rater.
+func MatchQuality(rater Rater, player1 Rating, player2 Rating) float64 {
+	return 1 - math.Abs(2*rater.Expected(player1, player2)-1)
+}
@@ -0,0 +1,34 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"math"
+	"testing"
+)
+
+// Test_MatchQuality runs unit tests for match quality.
+func Test_MatchQuality(t *testing.T) {
+	tests := []struct {
+		name    string
+		rater   Rater
+		player1 Rating
+		player2 Rating
+		want    float64
+	}{
+		{name: "equal", rater: NewCalculator(), player1: Rating{Value: 1200}, player2: Rating{Value: 1200}, want: 1},
+		{name: "10x better", rater: NewCalculator(), player1: Rating{Value: 1600}, player2: Rating{Value: 1200}, want: 2.0 / 11},
+		{name: "10x worse", rater: NewCalculator(), player1: Rating{Value: 1200}, player2: Rating{Value: 1600}, want: 2.0 / 11},
+		{name: "uncertain ratings are fairer", rater: Glicko2{}, player1: Rating{Value: 1600, Deviation: DefaultDeviation}, player2: Rating{Value: 1200, Deviation: DefaultDeviation}, want: 0.45},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchQuality(tt.rater, tt.player1, tt.player2); math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("MatchQuality() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+// Rating is a player's rating. Elo only uses the value, while Glicko-2 also uses the deviation (how uncertain the value
+// is) and the volatility (how consistent the player's results are).
+type Rating struct {
+	Value      float64
+	Deviation  float64
+	Volatility float64
+
+	// Games is the number of rated games the player had played before this rating is updated, which raters may use to
+	// decide how far the rating should move. It is not changed by raters.
+	Games uint32
+}
+
+// Result is the outcome of a single match against an opponent, from the point of view of the player being rated.
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+// Rater is a rating system.
+type Rater interface {
+
+	// Rate returns the new ratings for both players after a single match between them.
+	Rate(player1 Rating, player2 Rating, winner Player) (player1New Rating, player2New Rating)
+
+	// RatePeriod returns a player's new rating after a rating period in which they played the specified matches, all of
+	// which are rated against the player's rating at the start of the period. The results may be empty, if the player
+	// did not play during the period.
+	RatePeriod(player Rating, results []Result) Rating
+
+	// Expected returns the expected score for a player in a match against an opponent - the chance of the player
+	// winning, with a draw counted as half a win.
+	Expected(player Rating, opponent Rating) float64
+}
+
+// Scores returns the score for each player (1 for a win, 0.5 for a draw, and 0 for a loss) based on the winner.
+func Scores(winner Player) (player1Score float64, player2Score float64) {
+	if winner == Player1 {
+		return winScore, lossScore
+	} else if winner == Draw {
+		return drawScore, drawScore
+	}
+
+	return lossScore, winScore
+}
@@ -0,0 +1,41 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import "math"
+
+// RepeatPolicy reduces the rating that players can gain from playing the same opponent repeatedly, so that two
+// accounts can't farm rating by playing each other with one of them losing on purpose. Rating losses are not reduced.
+type RepeatPolicy struct {
+
+	// FreeMatches is the number of recent matches a pair of players can play against each other before gains are
+	// reduced.
+	FreeMatches int
+
+	// Factor is the multiplier applied to gains for each recent match between the pair beyond FreeMatches, so that
+	// gains shrink geometrically. It should be between 0 and 1.
+	Factor float64
+}
+
+// Multiplier returns the multiplier for rating gains in a match between two players who have recently played the
+// specified number of matches against each other, not including this one.
+func (p *RepeatPolicy) Multiplier(recent int) float64 {
+	if recent < p.FreeMatches {
+		return 1
+	}
+
+	return math.Pow(p.Factor, float64(recent-p.FreeMatches+1))
+}
+
+// Apply returns the rating after a match, with any gain from the rating before the match reduced by the multiplier
+// for the specified number of recent matches between the pair. Everything other than the value is unchanged.
+func (p *RepeatPolicy) Apply(before Rating, after Rating, recent int) Rating {
+	if after.Value > before.Value {
+		after.Value = before.Value + math.Round((after.Value-before.Value)*p.Multiplier(recent))
+	}
+
+	return after
+}
@@ -0,0 +1,36 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"testing"
+)
+
+// Test_RepeatPolicy_Apply runs unit tests for reducing gains from repeated matches.
+func Test_RepeatPolicy_Apply(t *testing.T) {
+	policy := &RepeatPolicy{FreeMatches: 2, Factor: 0.5}
+
+	tests := []struct {
+		name   string
+		before Rating
+		after  Rating
+		recent int
+		want   Rating
+	}{
+		{name: "first match", before: Rating{Value: 1200}, after: Rating{Value: 1216}, recent: 0, want: Rating{Value: 1216}},
+		{name: "last free match", before: Rating{Value: 1200}, after: Rating{Value: 1216}, recent: 1, want: Rating{Value: 1216}},
+		{name: "first reduced match", before: Rating{Value: 1200}, after: Rating{Value: 1216}, recent: 2, want: Rating{Value: 1208}},
+		{name: "second reduced match", before: Rating{Value: 1200}, after: Rating{Value: 1216}, recent: 3, want: Rating{Value: 1204}},
+		{name: "losses are not reduced", before: Rating{Value: 1200}, after: Rating{Value: 1184, Deviation: 100}, recent: 5, want: Rating{Value: 1184, Deviation: 100}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.Apply(tt.before, tt.after, tt.recent); got != tt.want {
+				t.Errorf("Apply() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+// TierPolicy places players into named tiers and divisions based on their rating. A player is only promoted once
+// their rating is PromotionBuffer above the bottom of the higher division, and only demoted once it is DemotionBuffer
+// below the bottom of their current division, so that a player hovering at a boundary doesn't flicker between them.
+type TierPolicy struct {
+
+	// Tiers are the tiers that players are placed into, in ascending order of rating.
+	Tiers []Tier
+
+	// PromotionBuffer is how far above the bottom of a higher division a player's rating must be before they are
+	// promoted into it.
+	PromotionBuffer float64
+
+	// DemotionBuffer is how far below the bottom of their current division a player's rating must be before they are
+	// demoted out of it.
+	DemotionBuffer float64
+}
+
+// Tier is a named range of ratings, from MinRating up to the MinRating of the next tier, split evenly into divisions.
+// Players rated below the lowest tier are placed in its lowest division. The highest tier has no upper bound, so it
+// always has a single division.
+type Tier struct {
+	Name      string
+	MinRating float64
+	Divisions uint8
+}
+
+// Placement is a player's tier and division. Divisions are numbered from 1 (the highest division in the tier) up to
+// the number of divisions in the tier. The zero value is not a valid placement.
+type Placement struct {
+	Tier     string
+	Division uint8
+}
+
+// step is a single division, with the rating at which it starts.
+type step struct {
+	placement Placement
+	minRating float64
+}
+
+// Place returns the placement for the specified rating, ignoring the buffers.
+func (p *TierPolicy) Place(rating float64) Placement {
+	ladder := p.ladder()
+	if len(ladder) == 0 {
+		return Placement{}
+	}
+
+	return ladder[stepFor(ladder, rating)].placement
+}
+
+// Update returns the new placement for a player with the specified previous placement, after their rating changed to
+// the specified rating, applying the buffers. If the previous placement is not valid for this policy (for example, if
+// the player has not been placed yet, or the tiers have changed), the buffers are ignored.
+func (p *TierPolicy) Update(previous Placement, rating float64) Placement {
+	ladder := p.ladder()
+	if len(ladder) == 0 {
+		return Placement{}
+	}
+
+	current := stepOf(ladder, previous)
+	if current < 0 {
+		return ladder[stepFor(ladder, rating)].placement
+	}
+
+	if next := stepFor(ladder, rating-p.PromotionBuffer); next > current {
+		return ladder[next].placement
+	}
+
+	if next := stepFor(ladder, rating+p.DemotionBuffer); next < current {
+		return ladder[next].placement
+	}
+
+	return previous
+}
+
+// Compare returns a positive number if placement a is higher than placement b, a negative number if it is lower, and
+// zero if they are the same. Invalid placements are lower than every valid placement.
+func (p *TierPolicy) Compare(a Placement, b Placement) int {
+	ladder := p.ladder()
+
+	return stepOf(ladder, a) - stepOf(ladder, b)
+}
+
+// ladder returns every division in the policy, from lowest to highest.
+func (p *TierPolicy) ladder() (ladder []step) {
+	for i, tier := range p.Tiers {
+		divisions := tier.Divisions
+		if divisions == 0 || i == len(p.Tiers)-1 {
+			divisions = 1
+		}
+
+		var width float64
+		if i < len(p.Tiers)-1 {
+			width = (p.Tiers[i+1].MinRating - tier.MinRating) / float64(divisions)
+		}
+
+		for division := divisions; division >= 1; division-- {
+			ladder = append(ladder, step{
+				placement: Placement{Tier: tier.Name, Division: division},
+				minRating: tier.MinRating + width*float64(divisions-division),
+			})
+		}
+	}
+
+	return ladder
+}
+
+// stepFor returns the index of the highest division in the ladder that the specified rating reaches, or the lowest
+// division if it reaches none of them.
+func stepFor(ladder []step, rating float64) (index int) {
+	for i, s := range ladder {
+		if rating < s.minRating {
+			break
+		}
+
+		index = i
+	}
+
+	return index
+}
+
+// stepOf returns the index of the specified placement in the ladder, or -1 if it is not found.
+func stepOf(ladder []step, placement Placement) int {
+	for i, s := range ladder {
+		if s.placement == placement {
+			return i
+		}
+	}
+
+	return -1
+}
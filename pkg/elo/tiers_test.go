@@ -0,0 +1,76 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package elo implements an elo calculator, based on https://www.youtube.com/watch?v=AsYfbmp0To0.
+package elo
+
+import (
+	"testing"
+)
+
+// testTierPolicy has two tiers of four divisions each, 50 rating points wide, and a top tier.
+var testTierPolicy = &TierPolicy{
+	Tiers: []Tier{
+		{Name: "Silver", MinRating: 1000, Divisions: 4},
+		{Name: "Gold", MinRating: 1200, Divisions: 4},
+		{Name: "Master", MinRating: 1400, Divisions: 4},
+	},
+	PromotionBuffer: 10,
+	DemotionBuffer:  20,
+}
+
+// Test_TierPolicy_Place runs unit tests for placing ratings into tiers and divisions.
+func Test_TierPolicy_Place(t *testing.T) {
+	tests := []struct {
+		name   string
+		rating float64
+		want   Placement
+	}{
+		{name: "below the lowest tier", rating: 500, want: Placement{Tier: "Silver", Division: 4}},
+		{name: "bottom of a tier", rating: 1200, want: Placement{Tier: "Gold", Division: 4}},
+		{name: "bottom of a division", rating: 1250, want: Placement{Tier: "Gold", Division: 3}},
+		{name: "top of a tier", rating: 1399, want: Placement{Tier: "Gold", Division: 1}},
+		{name: "highest tier", rating: 3000, want: Placement{Tier: "Master", Division: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testTierPolicy.Place(tt.rating); got != tt.want {
+				t.Errorf("Place() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Test_TierPolicy_Update runs unit tests for moving between divisions with buffers.
+func Test_TierPolicy_Update(t *testing.T) {
+	gold4 := Placement{Tier: "Gold", Division: 4}
+
+	tests := []struct {
+		name     string
+		previous Placement
+		rating   float64
+		want     Placement
+	}{
+		{name: "unplaced", previous: Placement{}, rating: 1255, want: Placement{Tier: "Gold", Division: 3}},
+		{name: "inside the promotion buffer", previous: gold4, rating: 1255, want: gold4},
+		{name: "past the promotion buffer", previous: gold4, rating: 1260, want: Placement{Tier: "Gold", Division: 3}},
+		{name: "skipping divisions", previous: gold4, rating: 1360, want: Placement{Tier: "Gold", Division: 1}},
+		{name: "inside the demotion buffer", previous: gold4, rating: 1180, want: gold4},
+		{name: "past the demotion buffer", previous: gold4, rating: 1179, want: Placement{Tier: "Silver", Division: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := testTierPolicy.Update(tt.previous, tt.rating); got != tt.want {
+				t.Errorf("Update() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if testTierPolicy.Compare(Placement{Tier: "Gold", Division: 3}, gold4) <= 0 {
+		t.Errorf("Compare() Gold 3 should be higher than Gold 4")
+	}
+	if testTierPolicy.Compare(Placement{}, Placement{Tier: "Silver", Division: 4}) >= 0 {
+		t.Errorf("Compare() an invalid placement should be lower than every valid placement")
+	}
+}
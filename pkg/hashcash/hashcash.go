@@ -0,0 +1,223 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package hashcash implements a stateless, hashcash style proof of work challenge. The server issues a challenge signed
+// with a secret, and the client must find a nonce such that the SHA-256 hash of the challenge and nonce starts with a
+// number of zero bits (the difficulty). As the challenge is signed, the server does not need to store it in order to
+// verify the solution - it only needs to remember the challenges that have been redeemed, until they expire, so that
+// each solution can only be used once.
+package hashcash
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxDifficulty is the highest difficulty that can be issued, as a number of leading zero bits.
+const MaxDifficulty = 32
+
+// saltLength is the number of random bytes in each challenge, so that no two challenges are the same.
+const saltLength = 16
+
+// Errors returned when verifying a solution.
+var (
+	ErrMalformed = errors.New("Challenge is malformed")
+	ErrSignature = errors.New("Challenge signature is invalid")
+	ErrExpired   = errors.New("Challenge has expired")
+	ErrSolution  = errors.New("Challenge solution is incorrect")
+	ErrSpent     = errors.New("Challenge has already been used")
+	ErrEasy      = errors.New("Challenge difficulty is too low")
+)
+
+// Spender records the challenges that have been redeemed, so that each can only be redeemed once.
+type Spender interface {
+
+	// Spend records the challenge with the specified ID as redeemed, and returns false if it already was. The ID only
+	// needs to be remembered until the challenge expires, as expired challenges are rejected anyway.
+	Spend(id string, expires time.Time) (ok bool, err error)
+}
+
+// Issuer issues and verifies challenges, signed with a secret.
+type Issuer struct {
+	secret   []byte
+	lifetime time.Duration
+
+	// now returns the current time, and can be replaced for testing.
+	now func() time.Time
+}
+
+// NewIssuer creates an issuer that signs challenges with the specified secret. Challenges expire after the specified
+// lifetime, which should be short, as a challenge's difficulty can't change once it is issued.
+func NewIssuer(secret []byte, lifetime time.Duration) *Issuer {
+	return &Issuer{
+		secret:   secret,
+		lifetime: lifetime,
+		now:      time.Now,
+	}
+}
+
+// Issue creates a new challenge with the specified difficulty (in leading zero bits), bound to the specified subject
+// (such as the IP address of the client), and returns it along with the time it expires. The subject is not included
+// in the challenge, but is part of its signature, so the same subject must be given when verifying the solution.
+func (i *Issuer) Issue(subject string, difficulty uint8) (challenge string, expires time.Time, err error) {
+	if difficulty > MaxDifficulty {
+		return challenge, expires, fmt.Errorf("Difficulty must be %v or less", MaxDifficulty)
+	}
+
+	// Create a random salt. Exit early on error.
+	salt := make([]byte, saltLength)
+	_, err = rand.Read(salt)
+	if err != nil {
+		return challenge, expires, err
+	}
+
+	// The challenge is the difficulty, expiry and salt, followed by the signature for them (and the subject).
+	expires = i.now().Add(i.lifetime)
+	payload := fmt.Sprintf("%v.%v.%v", difficulty, expires.Unix(), hex.EncodeToString(salt))
+
+	return payload + "." + i.sign(subject, payload), expires, nil
+}
+
+// Verify returns nil if the nonce solves the challenge, and the challenge was issued by this issuer for the specified
+// subject and has not expired. Otherwise, one of the errors defined by this package is returned.
+func (i *Issuer) Verify(subject string, challenge string, nonce string) error {
+
+	// Split the challenge into its payload and signature, and check the signature first, so that nothing else in
+	// the challenge is trusted until it is known to have been issued by this issuer.
+	separator := strings.LastIndex(challenge, ".")
+	if separator == -1 {
+		return ErrMalformed
+	}
+
+	payload, signature := challenge[:separator], challenge[separator+1:]
+	if !hmac.Equal([]byte(signature), []byte(i.sign(subject, payload))) {
+		return ErrSignature
+	}
+
+	difficulty, expires, _, err := parse(payload)
+	if err != nil {
+		return err
+	}
+
+	if i.now().After(expires) {
+		return ErrExpired
+	}
+
+	if !Solves(challenge, nonce, difficulty) {
+		return ErrSolution
+	}
+
+	return nil
+}
+
+// VerifyDifficulty verifies the solution in the same way as Verify, and also requires the challenge to have at least the
+// specified difficulty, so that a challenge issued before the subject's difficulty increased can't be used once it has.
+// The challenge is not spent, so this can be used to check a solution before anything else about a request, and the
+// challenge redeemed once the request is known to be valid.
+func (i *Issuer) VerifyDifficulty(subject string, challenge string, nonce string, minDifficulty uint8) error {
+	err := i.Verify(subject, challenge, nonce)
+	if err != nil {
+		return err
+	}
+
+	// The challenge has been verified, so it is well formed.
+	difficulty, _, _, _ := parse(challenge[:strings.LastIndex(challenge, ".")])
+	if difficulty < minDifficulty {
+		return ErrEasy
+	}
+
+	return nil
+}
+
+// Redeem verifies the solution in the same way as VerifyDifficulty, and then spends the challenge, so that the same
+// solution can't be redeemed again. Returns nil if the challenge was redeemed, one of the errors defined by this package,
+// or the error returned by the spender.
+func (i *Issuer) Redeem(subject string, challenge string, nonce string, minDifficulty uint8, spender Spender) error {
+	err := i.VerifyDifficulty(subject, challenge, nonce, minDifficulty)
+	if err != nil {
+		return err
+	}
+
+	// Spend the challenge, identified by its salt, which is unique to each challenge.
+	_, expires, salt, _ := parse(challenge[:strings.LastIndex(challenge, ".")])
+	ok, err := spender.Spend(salt, expires)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return ErrSpent
+	}
+
+	return nil
+}
+
+// sign returns the signature for the specified subject and payload.
+func (i *Issuer) sign(subject string, payload string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(subject))
+	mac.Write([]byte{0})
+	mac.Write([]byte(payload))
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// parse returns the difficulty, expiry and salt from a challenge payload.
+func parse(payload string) (difficulty uint8, expires time.Time, salt string, err error) {
+	fields := strings.Split(payload, ".")
+	if len(fields) != 3 {
+		return difficulty, expires, salt, ErrMalformed
+	}
+
+	parsedDifficulty, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil || parsedDifficulty > MaxDifficulty {
+		return difficulty, expires, salt, ErrMalformed
+	}
+
+	unix, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return difficulty, expires, salt, ErrMalformed
+	}
+
+	return uint8(parsedDifficulty), time.Unix(unix, 0), fields[2], nil
+}
+
+// Solves returns true if the SHA-256 hash of the challenge and nonce, joined by a ":", starts with at least the
+// specified number of zero bits.
+func Solves(challenge string, nonce string, difficulty uint8) bool {
+	return leadingZeroBits(sha256.Sum256([]byte(challenge+":"+nonce))) >= int(difficulty)
+}
+
+// Solve finds a nonce that solves the challenge with the specified difficulty, by trying each number in turn. This is
+// how clients are expected to solve challenges, and takes around 2^difficulty attempts.
+func Solve(challenge string, difficulty uint8) (nonce string) {
+	for n := uint64(0); ; n++ {
+		nonce = strconv.FormatUint(n, 10)
+		if Solves(challenge, nonce, difficulty) {
+			return nonce
+		}
+	}
+}
+
+// leadingZeroBits returns the number of leading zero bits in the hash.
+func leadingZeroBits(hash [sha256.Size]byte) (count int) {
+	for _, b := range hash {
+		if b != 0 {
+			return count + bits.LeadingZeros8(b)
+		}
+
+		count += 8
+	}
+
+	return count
+}
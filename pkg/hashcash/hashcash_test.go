@@ -0,0 +1,149 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package hashcash implements a stateless, hashcash style proof of work challenge.
+package hashcash
+
+import (
+	"crypto/sha256"
+	"testing"
+	"time"
+)
+
+// Test_Issuer_Verify runs unit tests for verifying solutions to issued challenges.
+func Test_Issuer_Verify(t *testing.T) {
+	issuer := NewIssuer([]byte("secret"), time.Minute)
+	challenge, _, err := issuer.Issue("127.0.0.1", 8)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	nonce := Solve(challenge, 8)
+
+	// Find a nonce that does not solve the challenge.
+	wrongNonce := "wrong"
+	for Solves(challenge, wrongNonce, 8) {
+		wrongNonce += "!"
+	}
+
+	// Create a challenge with the same payload, signed with a different secret.
+	forged, _, _ := NewIssuer([]byte("other secret"), time.Minute).Issue("127.0.0.1", 8)
+
+	// Create an issuer whose clock is past the challenge's expiry.
+	late := NewIssuer([]byte("secret"), time.Minute)
+	late.now = func() time.Time { return time.Now().Add(time.Minute * 2) }
+
+	tests := []struct {
+		name      string
+		issuer    *Issuer
+		subject   string
+		challenge string
+		nonce     string
+		wantErr   error
+	}{
+		{name: "solved", issuer: issuer, subject: "127.0.0.1", challenge: challenge, nonce: nonce, wantErr: nil},
+		{name: "wrong nonce", issuer: issuer, subject: "127.0.0.1", challenge: challenge, nonce: wrongNonce, wantErr: ErrSolution},
+		{name: "wrong subject", issuer: issuer, subject: "127.0.0.2", challenge: challenge, nonce: nonce, wantErr: ErrSignature},
+		{name: "wrong secret", issuer: issuer, subject: "127.0.0.1", challenge: forged, nonce: Solve(forged, 8), wantErr: ErrSignature},
+		{name: "lowered difficulty", issuer: issuer, subject: "127.0.0.1", challenge: "0" + challenge[1:], nonce: "0", wantErr: ErrSignature},
+		{name: "expired", issuer: late, subject: "127.0.0.1", challenge: challenge, nonce: nonce, wantErr: ErrExpired},
+		{name: "malformed", issuer: issuer, subject: "127.0.0.1", challenge: "challenge", nonce: nonce, wantErr: ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.issuer.Verify(tt.subject, tt.challenge, tt.nonce); err != tt.wantErr {
+				t.Errorf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// memorySpender is a spender that remembers spent challenges in memory, for testing.
+type memorySpender map[string]bool
+
+// Spend records the challenge as spent, and returns false if it already was.
+func (m memorySpender) Spend(id string, expires time.Time) (ok bool, err error) {
+	if m[id] {
+		return false, nil
+	}
+
+	m[id] = true
+
+	return true, nil
+}
+
+// Test_Issuer_Redeem ensures that a solved challenge can only be redeemed once, and only if it is at least as difficult
+// as required.
+func Test_Issuer_Redeem(t *testing.T) {
+	issuer := NewIssuer([]byte("secret"), time.Minute)
+	challenge, _, err := issuer.Issue("127.0.0.1", 8)
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+
+	nonce := Solve(challenge, 8)
+	spender := memorySpender{}
+
+	if err := issuer.Redeem("127.0.0.1", challenge, nonce, 9, spender); err != ErrEasy {
+		t.Errorf("Redeem() with a higher minimum difficulty error = %v, want %v", err, ErrEasy)
+	}
+	if err := issuer.Redeem("127.0.0.1", challenge, "wrong", 8, spender); err != ErrSolution {
+		t.Errorf("Redeem() with a wrong nonce error = %v, want %v", err, ErrSolution)
+	}
+
+	// Verifying the difficulty does not spend the challenge, so it can be checked any number of times before it is
+	// redeemed.
+	for range []int{1, 2} {
+		if err := issuer.VerifyDifficulty("127.0.0.1", challenge, nonce, 8); err != nil {
+			t.Errorf("VerifyDifficulty() error = %v, want nil", err)
+		}
+	}
+	if err := issuer.VerifyDifficulty("127.0.0.1", challenge, nonce, 9); err != ErrEasy {
+		t.Errorf("VerifyDifficulty() with a higher minimum difficulty error = %v, want %v", err, ErrEasy)
+	}
+
+	if err := issuer.Redeem("127.0.0.1", challenge, nonce, 8, spender); err != nil {
+		t.Errorf("Redeem() error = %v, want nil", err)
+	}
+	if err := issuer.Redeem("127.0.0.1", challenge, nonce, 8, spender); err != ErrSpent {
+		t.Errorf("Redeem() a second time error = %v, want %v", err, ErrSpent)
+	}
+
+	// A different challenge for the same subject can still be redeemed.
+	other, _, _ := issuer.Issue("127.0.0.1", 8)
+	if err := issuer.Redeem("127.0.0.1", other, Solve(other, 8), 8, spender); err != nil {
+		t.Errorf("Redeem() another challenge error = %v, want nil", err)
+	}
+}
+
+// Test_Issuer_Issue ensures that difficulties above the maximum are rejected.
+func Test_Issuer_Issue(t *testing.T) {
+	if _, _, err := NewIssuer([]byte("secret"), time.Minute).Issue("127.0.0.1", MaxDifficulty+1); err == nil {
+		t.Errorf("Issue() did not return an error for a difficulty above the maximum")
+	}
+}
+
+// Test_leadingZeroBits runs unit tests for counting leading zero bits.
+func Test_leadingZeroBits(t *testing.T) {
+	tests := []struct {
+		name  string
+		bytes []byte
+		want  int
+	}{
+		{name: "none", bytes: []byte{0x80}, want: 0},
+		{name: "partial byte", bytes: []byte{0x10}, want: 3},
+		{name: "whole byte", bytes: []byte{0x00, 0xff}, want: 8},
+		{name: "whole and partial bytes", bytes: []byte{0x00, 0x00, 0x01}, want: 23},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hash [sha256.Size]byte
+			copy(hash[:], tt.bytes)
+			hash[sha256.Size-1] = 1
+			if got := leadingZeroBits(hash); got != tt.want {
+				t.Errorf("leadingZeroBits() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
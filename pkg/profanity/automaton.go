@@ -0,0 +1,165 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+// runs is text split into runs of the same rune, so that "fuuck" has the letters "fuck" with the counts 1, 2, 1, 1.
+// Terms and input are both matched as runs, so that each letter in a term also matches that letter repeated any
+// number of times in the input.
+type runs struct {
+	letters []rune
+	counts  []int
+
+	// starts is the index of the first rune of each run, in the runes that the runs were created from.
+	starts []int
+}
+
+// toRuns splits the runes into runs of the same rune.
+func toRuns(input []rune) (r runs) {
+	for i, letter := range input {
+		if last := len(r.letters) - 1; last >= 0 && r.letters[last] == letter {
+			r.counts[last]++
+			continue
+		}
+
+		r.letters = append(r.letters, letter)
+		r.counts = append(r.counts, 1)
+		r.starts = append(r.starts, i)
+	}
+
+	return r
+}
+
+// runeRange returns the range of runes covered by the specified range of runs (start inclusive, end exclusive).
+func (r runs) runeRange(start int, end int) (runeStart int, runeEnd int) {
+	return r.starts[start], r.starts[end-1] + r.counts[end-1]
+}
+
+// matchesAt returns true if the input, from the specified run onwards, has the same letters as these runs, with each run
+// being at least as long. A term with a double letter (such as "piss") therefore only matches input where that letter is
+// repeated at least twice.
+func (r runs) matchesAt(input runs, start int) bool {
+	if start < 0 || start+len(r.letters) > len(input.letters) {
+		return false
+	}
+
+	for i, letter := range r.letters {
+		if input.letters[start+i] != letter || input.counts[start+i] < r.counts[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// key returns the letters as a string, without the counts, for use as a map key.
+func (r runs) key() string {
+	return string(r.letters)
+}
+
+// automaton is an Aho-Corasick automaton, which finds every occurrence of a set of patterns in an input in a single pass,
+// regardless of how many patterns there are. It operates on the letters of runs, and then checks the counts of each
+// candidate match, so that repeated letters in the input are matched without needing a pattern for each repetition.
+type automaton struct {
+	nodes    []node
+	patterns []runs
+}
+
+// node is a single state in an automaton.
+type node struct {
+
+	// next maps each letter to the state that follows it.
+	next map[rune]int
+
+	// fail is the state to fall back to when there is no state for the next letter - the state for the longest suffix of
+	// this state that is also a prefix of a pattern.
+	fail int
+
+	// outputs are the indexes of every pattern that ends at this state, including those ending at its fail states.
+	outputs []int
+}
+
+// newAutomaton builds an automaton that matches the specified patterns. Matches are reported using the index of the pattern.
+func newAutomaton(patterns []runs) *automaton {
+	a := &automaton{
+		nodes:    []node{{next: make(map[rune]int)}},
+		patterns: patterns,
+	}
+
+	// Add each pattern to the trie, creating states as required. Empty patterns are ignored, as they would match everything.
+	for index, pattern := range patterns {
+		if len(pattern.letters) == 0 {
+			continue
+		}
+
+		state := 0
+		for _, letter := range pattern.letters {
+			next, ok := a.nodes[state].next[letter]
+			if !ok {
+				next = len(a.nodes)
+				a.nodes = append(a.nodes, node{next: make(map[rune]int)})
+				a.nodes[state].next[letter] = next
+			}
+
+			state = next
+		}
+
+		a.nodes[state].outputs = append(a.nodes[state].outputs, index)
+	}
+
+	// Set the fail state for each state, breadth first, so that the fail state of each state's parent is always known.
+	// The outputs of each fail state are copied to the state, so that they don't need to be followed while scanning.
+	queue := make([]int, 0, len(a.nodes))
+	for _, child := range a.nodes[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		state := queue[0]
+		queue = queue[1:]
+
+		for letter, child := range a.nodes[state].next {
+			a.nodes[child].fail = a.step(a.nodes[state].fail, letter)
+			a.nodes[child].outputs = append(a.nodes[child].outputs, a.nodes[a.nodes[child].fail].outputs...)
+			queue = append(queue, child)
+		}
+	}
+
+	return a
+}
+
+// step returns the state that follows the specified state for the specified letter, following fail states as required.
+func (a *automaton) step(state int, letter rune) int {
+	for {
+		if next, ok := a.nodes[state].next[letter]; ok {
+			return next
+		}
+
+		if state == 0 {
+			return 0
+		}
+
+		state = a.nodes[state].fail
+	}
+}
+
+// scan calls fn for every occurrence of every pattern in the input, with the index of the pattern and the range of runs
+// that it covers in the input (start inclusive, end exclusive). Scanning stops early if fn returns false.
+func (a *automaton) scan(input runs, fn func(pattern int, start int, end int) bool) {
+	state := 0
+	for i, letter := range input.letters {
+		state = a.step(state, letter)
+		for _, pattern := range a.nodes[state].outputs {
+			start := i + 1 - len(a.patterns[pattern].letters)
+			if !a.patterns[pattern].matchesAt(input, start) {
+				continue
+			}
+
+			if !fn(pattern, start, i+1) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+import (
+	"sort"
+)
+
+// CensorOptions controls which matches are masked by Censor, and how.
+type CensorOptions struct {
+
+	// Mask is the rune that replaces masked runes. Defaults to "*" if zero.
+	Mask rune
+
+	// KeepFirst and KeepLast leave the first and last runes of each masked span as they are, so that "fuck" becomes
+	// "f***" or "f**k". Whitespace and separators are never masked, so that the layout of the text is kept.
+	KeepFirst bool
+	KeepLast  bool
+
+	// Severity is the minimum severity that is masked. Matches with a lower severity are still returned, but the text
+	// that they cover is left as it is.
+	Severity Severity
+}
+
+// DefaultCensorOptions masks everything that should not be shown in public, such as in public chat.
+var DefaultCensorOptions = CensorOptions{
+	Mask:     '*',
+	Severity: AllowInPrivate,
+}
+
+// Span is a range of runes in an input that matched a term.
+type Span struct {
+	Match
+
+	// Start and End are rune offsets into the input (start inclusive, end exclusive).
+	Start int
+	End   int
+
+	// Masked is true if the span was masked, based on its severity.
+	Masked bool
+}
+
+// Censor returns the input with the text covered by each match masked, along with every match and the range of runes
+// that it covers, using the package's filter. See Dictionary.Censor for details.
+func Censor(input string, options CensorOptions) (censored string, spans []Span) {
+	return defaultFilter.Dictionary().Censor(input, options)
+}
+
+// Censor returns the input with the text covered by each match masked, along with every match and the range of runes
+// that it covers, sorted by where they start. Overlapping matches are all returned, and are masked together.
+func (d *Dictionary) Censor(input string, options CensorOptions) (censored string, spans []Span) {
+	if options.Mask == 0 {
+		options.Mask = DefaultCensorOptions.Mask
+	}
+
+	// Collect every match, ignoring duplicates of the same term for the same range (from different forms of the input).
+	type key struct {
+		term       string
+		start, end int
+	}
+
+	seen := make(map[key]bool)
+	d.scan(input, func(entry Entry, start int, end int) bool {
+		k := key{term: entry.Term, start: start, end: end}
+		if !seen[k] {
+			seen[k] = true
+			spans = append(spans, Span{
+				Match:  Match{Entry: entry, Reason: reason(entry)},
+				Start:  start,
+				End:    end,
+				Masked: entry.Severity >= options.Severity,
+			})
+		}
+
+		return true
+	})
+
+	// Early exit if nothing matched, which is by far the most common case.
+	if len(spans) == 0 {
+		return input, spans
+	}
+
+	sort.SliceStable(spans, func(i int, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+
+		return spans[i].End < spans[j].End
+	})
+
+	// Mask the runes covered by each masked span.
+	runes := []rune(input)
+	masked := make([]bool, len(runes))
+	for _, span := range spans {
+		if span.Masked {
+			maskSpan(runes, masked, span.Start, span.End, options)
+		}
+	}
+
+	for i := range runes {
+		if masked[i] {
+			runes[i] = options.Mask
+		}
+	}
+
+	return string(runes), spans
+}
+
+// maskSpan marks the runes in the specified range to be masked, skipping separators, and the first and last letters
+// if required by the options.
+func maskSpan(runes []rune, masked []bool, start int, end int, options CensorOptions) {
+
+	// Find the first and last letters in the span, which may be kept.
+	first, last := -1, -1
+	for i := start; i < end; i++ {
+		if !isMaskSeparator(runes[i]) {
+			if first == -1 {
+				first = i
+			}
+
+			last = i
+		}
+	}
+
+	for i := start; i < end; i++ {
+		if isMaskSeparator(runes[i]) || (options.KeepFirst && i == first) || (options.KeepLast && i == last) {
+			continue
+		}
+
+		masked[i] = true
+	}
+}
+
+// isMaskSeparator returns true if the rune should never be masked. Leetspeak characters (such as "$") are masked, as
+// they are part of the word.
+func isMaskSeparator(r rune) bool {
+	if _, leet := leetspeak[r]; leet {
+		return false
+	}
+
+	return isSeparator(r)
+}
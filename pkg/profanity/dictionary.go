@@ -0,0 +1,223 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+// Dictionary is a set of word lists compiled for matching. A dictionary is immutable once compiled, so it is safe for
+// concurrent use.
+type Dictionary struct {
+	entries []Entry
+
+	// normalized matches entries against normalized input (see normalize), or each word in it.
+	normalized patternSet
+
+	// compacted matches entries against compacted input (see compact), or each word in it, which has had separators
+	// removed and leetspeak replaced.
+	compacted patternSet
+
+	// allowed matches the words in the allowlists.
+	allowed *automaton
+}
+
+// pattern is a single form of an entry, as runs.
+type pattern struct {
+	runs
+
+	// entry is the index of the entry in the dictionary, and mode is how it should be matched.
+	entry int
+	mode  MatchMode
+}
+
+// patternSet is the compiled patterns for a single form of the input. Substring patterns are matched with an automaton,
+// while whole word and exact patterns are looked up by their letters, as they must match an entire word or input.
+type patternSet struct {
+	substrings []pattern
+	automaton  *automaton
+	anchored   map[string][]pattern
+}
+
+// Compile compiles the specified word lists into a dictionary. The terms are normalized in the same way as the input, so
+// that (for example) katakana words also match their hiragana spellings.
+func Compile(lists ...List) *Dictionary {
+	d := &Dictionary{
+		normalized: patternSet{anchored: make(map[string][]pattern)},
+		compacted:  patternSet{anchored: make(map[string][]pattern)},
+	}
+
+	allowed := make([]runs, 0)
+	for _, list := range lists {
+		for _, entry := range list.Entries {
+			index := len(d.entries)
+			d.entries = append(d.entries, entry)
+			d.normalized.add(pattern{runs: toRuns([]rune(normalize(entry.Term))), entry: index, mode: entry.Mode})
+			d.compacted.add(pattern{runs: toRuns([]rune(stripSeparators(normalize(entry.Term)))), entry: index, mode: entry.Mode})
+		}
+
+		for _, word := range list.Allowlist {
+			allowed = append(allowed, toRuns([]rune(stripSeparators(normalize(word)))))
+		}
+	}
+
+	d.normalized.compile()
+	d.compacted.compile()
+	d.allowed = newAutomaton(allowed)
+
+	return d
+}
+
+// Entries returns the number of entries in the dictionary.
+func (d *Dictionary) Entries() int {
+	return len(d.entries)
+}
+
+// ContainsProfanity returns true if the provided input is profane, or contains profanity, along with the most severe
+// term that was matched and why.
+//
+// The input is normalized before matching, so that common evasions such as full-width characters, look-alike characters,
+// katakana instead of hiragana, leetspeak, separators between letters, and repeated letters are still caught. Matches
+// that are entirely within a word in the allowlist (such as "Smith", which contains "sm") are ignored, but matches that
+// overlap the edge of an allowed word are not.
+func (d *Dictionary) ContainsProfanity(input string) (profane bool, match Match) {
+
+	// Keep the most severe match, and stop once a blocked term is matched, as nothing is more severe.
+	d.scan(input, func(entry Entry, _ int, _ int) bool {
+		if !profane || entry.Severity > match.Severity {
+			profane = true
+			match = Match{Entry: entry, Reason: reason(entry)}
+		}
+
+		return match.Severity != Block
+	})
+
+	return profane, match
+}
+
+// scan calls fn for every term that matches the input, with the range of runes in the input that the match covers (start
+// inclusive, end exclusive). The same term may be reported more than once for the same range, as each form of the input
+// is matched separately. Scanning stops early if fn returns false.
+func (d *Dictionary) scan(input string, fn func(entry Entry, start int, end int) bool) {
+
+	// Create the normalized and compacted forms of the input, and find the allowed words in both. The allowed words are
+	// left in place, so that a term which overlaps the edge of an allowed word is still matched.
+	normalized := normalizeText(input)
+	compacted := normalized.compact()
+	allowed := d.allowedSpans(normalized, compacted)
+
+	// report passes each matching pattern to fn, with its range in the input, unless the match is entirely within an
+	// allowed word.
+	report := func(p pattern, start int, end int) bool {
+		if allowed.contains(start, end) {
+			return true
+		}
+
+		return fn(d.entries[p.entry], start, end)
+	}
+
+	// Substring terms are matched anywhere in both forms of the input, in a single pass each.
+	if !d.normalized.find(normalized, report) || !d.compacted.find(compacted, report) {
+		return
+	}
+
+	// Whole word terms are matched against each word in the normalized input, and the compacted form of that word.
+	for _, word := range normalized.words() {
+		if !d.normalized.lookup(word, WholeWord, report) || !d.compacted.lookup(word.compact(), WholeWord, report) {
+			return
+		}
+	}
+
+	// Exact terms are matched against the entire input.
+	if !d.normalized.lookup(normalized, Exact, report) {
+		return
+	}
+
+	d.compacted.lookup(compacted.trimSpace(), Exact, report)
+}
+
+// spans is a set of ranges of runes in the input (start inclusive, end exclusive).
+type spans [][2]int
+
+// contains returns true if the specified range is entirely within one of the spans.
+func (s spans) contains(start int, end int) bool {
+	for _, span := range s {
+		if span[0] <= start && end <= span[1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedSpans returns the range of runes in the input covered by each allowed word found in any of the forms of the
+// input.
+func (d *Dictionary) allowedSpans(forms ...text) (allowed spans) {
+	for _, t := range forms {
+		r := toRuns(t.runes)
+		d.allowed.scan(r, func(_ int, start int, end int) bool {
+			start, end = t.span(r.runeRange(start, end))
+			allowed = append(allowed, [2]int{start, end})
+			return true
+		})
+	}
+
+	return allowed
+}
+
+// add adds the pattern to the set.
+func (s *patternSet) add(p pattern) {
+	if len(p.letters) == 0 {
+		return
+	}
+
+	if p.mode == Substring {
+		s.substrings = append(s.substrings, p)
+		return
+	}
+
+	s.anchored[p.key()] = append(s.anchored[p.key()], p)
+}
+
+// compile builds the automaton for the substring patterns in the set.
+func (s *patternSet) compile() {
+	patterns := make([]runs, len(s.substrings))
+	for i, p := range s.substrings {
+		patterns[i] = p.runs
+	}
+
+	s.automaton = newAutomaton(patterns)
+}
+
+// find calls fn for each substring pattern found in the text, with the range of runes in the original input that it
+// covers, and returns false if fn returned false.
+func (s *patternSet) find(t text, fn func(p pattern, start int, end int) bool) (ok bool) {
+	ok = true
+	r := toRuns(t.runes)
+	s.automaton.scan(r, func(index int, start int, end int) bool {
+		start, end = t.span(r.runeRange(start, end))
+		ok = fn(s.substrings[index], start, end)
+		return ok
+	})
+
+	return ok
+}
+
+// lookup calls fn for each pattern that matches the entire text using the specified mode, with the range of runes in
+// the original input that the text covers, and returns false if fn returned false.
+func (s *patternSet) lookup(t text, mode MatchMode, fn func(p pattern, start int, end int) bool) bool {
+	if len(t.runes) == 0 {
+		return true
+	}
+
+	r := toRuns(t.runes)
+	start, end := t.span(0, len(t.runes))
+	for _, p := range s.anchored[r.key()] {
+		if p.mode == mode && p.matchesAt(r, 0) {
+			if !fn(p, start, end) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
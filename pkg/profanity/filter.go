@@ -0,0 +1,128 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+import (
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// defaultFilter is the filter used by ContainsProfanity. By default it contains the embedded word lists for every
+// locale, and is never reloaded.
+var defaultFilter = mustFilter(NewFilter(0, Embedded()))
+
+// Filter holds a dictionary compiled from one or more sources. Once the dictionary is older than the filter's time to
+// live, it is reloaded in the background while the old dictionary continues to be used, so changes to the sources are
+// picked up by a warm lambda without waiting for a redeploy or a cold start.
+type Filter struct {
+	sources []Source
+	ttl     time.Duration
+
+	// dictionary is the current *Dictionary, and loaded is when it was loaded (in unix nanoseconds).
+	dictionary atomic.Value
+	loaded     int64
+
+	// reloading is set to 1 while a reload is in progress, so that only one happens at a time.
+	reloading int32
+}
+
+// Configure replaces the filter used by ContainsProfanity with a new filter for the specified sources. It should be
+// called once during initialization, before ContainsProfanity is used.
+//
+// If any of the sources fail to load, an error is returned, but the filter is still replaced with one using the
+// sources that did load.
+func Configure(ttl time.Duration, sources ...Source) (err error) {
+	filter, err := NewFilter(ttl, sources...)
+	defaultFilter = filter
+
+	return err
+}
+
+// NewFilter creates a filter, loading its dictionary from the specified sources. A ttl of zero means that the
+// dictionary is never reloaded.
+//
+// If any of the sources fail to load, an error is returned along with a filter using the sources that did load, so
+// that (for example) the embedded lists are still used if a database is unavailable.
+func NewFilter(ttl time.Duration, sources ...Source) (filter *Filter, err error) {
+	filter = &Filter{
+		sources: sources,
+		ttl:     ttl,
+	}
+
+	dictionary, err := load(sources)
+	filter.store(dictionary)
+
+	return filter, err
+}
+
+// Dictionary returns the filter's current dictionary. If it is older than the filter's time to live, a reload is
+// started in the background, and the current dictionary is returned without waiting for it.
+func (f *Filter) Dictionary() *Dictionary {
+	if f.ttl > 0 && time.Since(time.Unix(0, atomic.LoadInt64(&f.loaded))) > f.ttl {
+		if atomic.CompareAndSwapInt32(&f.reloading, 0, 1) {
+			go func() {
+				defer atomic.StoreInt32(&f.reloading, 0)
+				if err := f.Reload(); err != nil {
+					log.Printf("Failed to reload profanity dictionary: %v", err)
+				}
+			}()
+		}
+	}
+
+	return f.dictionary.Load().(*Dictionary)
+}
+
+// Reload loads the filter's dictionary from its sources. If any of the sources fail to load, the current dictionary is
+// kept and an error is returned, so that a temporary failure does not remove terms. Either way, the next reload will
+// not happen until the time to live has passed again.
+func (f *Filter) Reload() error {
+	dictionary, err := load(f.sources)
+	if err != nil {
+		atomic.StoreInt64(&f.loaded, time.Now().UnixNano())
+		return err
+	}
+
+	f.store(dictionary)
+	return nil
+}
+
+// store sets the filter's current dictionary.
+func (f *Filter) store(dictionary *Dictionary) {
+	f.dictionary.Store(dictionary)
+	atomic.StoreInt64(&f.loaded, time.Now().UnixNano())
+}
+
+// load compiles a dictionary from the lists in each of the specified sources. Sources that fail to load are skipped,
+// and the first error is returned.
+func load(sources []Source) (dictionary *Dictionary, err error) {
+	lists := make([]List, 0, len(sources))
+	for i, source := range sources {
+		list, sourceErr := source.Load()
+		if sourceErr != nil {
+			if err == nil {
+				err = fmt.Errorf("Source %v: %v", i, sourceErr)
+			}
+
+			continue
+		}
+
+		lists = append(lists, list)
+	}
+
+	return Compile(lists...), err
+}
+
+// mustFilter panics if err is not nil, and otherwise returns the filter. Used for filters that only use the embedded
+// lists, which would only fail to load if they were invalid.
+func mustFilter(filter *Filter, err error) *Filter {
+	if err != nil {
+		panic(err)
+	}
+
+	return filter
+}
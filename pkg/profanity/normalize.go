@@ -0,0 +1,108 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Katakana that have a hiragana equivalent are in this range, and are offset from their equivalents by a fixed amount.
+const (
+	katakanaStart  = 'ァ'
+	katakanaEnd    = 'ヶ'
+	katakanaOffset = 'ァ' - 'ぁ'
+)
+
+// normalize returns the input with compatibility characters replaced (NFKC - so full-width latin becomes half-width, and
+// half-width katakana becomes full-width), in lower case, with look-alike characters from other scripts replaced by their
+// latin equivalents, and with katakana replaced by hiragana.
+func normalize(input string) string {
+	return normalizeText(input).String()
+}
+
+// compact returns the normalized input with leetspeak replaced by the letters it represents, and with all separators
+// (whitespace, punctuation and symbols) removed, so that "f.u.c.k" and "5 h 1 t" are matched as words.
+func compact(normalized string) string {
+	return stripSeparators(strings.Map(leetRune, normalized))
+}
+
+// stripSeparators removes all whitespace, punctuation and symbols from the input.
+func stripSeparators(input string) string {
+	return strings.Map(func(r rune) rune {
+		if isSeparator(r) {
+			return -1
+		}
+
+		return r
+	}, input)
+}
+
+// isSeparator returns true if the rune is whitespace, punctuation or a symbol.
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
+
+// foldRune replaces look-alike characters with their latin equivalents, and katakana with hiragana. Used with strings.Map.
+func foldRune(r rune) rune {
+	if folded, ok := homoglyphs[r]; ok {
+		return folded
+	}
+
+	if r >= katakanaStart && r <= katakanaEnd {
+		return r - katakanaOffset
+	}
+
+	return r
+}
+
+// leetRune replaces leetspeak characters with the letters that they represent. Used with strings.Map.
+func leetRune(r rune) rune {
+	if letter, ok := leetspeak[r]; ok {
+		return letter
+	}
+
+	return r
+}
+
+// homoglyphs maps lower case greek and cyrillic characters to the latin characters that they look like.
+var homoglyphs = map[rune]rune{
+	'α': 'a', // GREEK SMALL LETTER ALPHA
+	'ι': 'i', // GREEK SMALL LETTER IOTA
+	'κ': 'k', // GREEK SMALL LETTER KAPPA
+	'μ': 'u', // GREEK SMALL LETTER MU
+	'ν': 'v', // GREEK SMALL LETTER NU
+	'ο': 'o', // GREEK SMALL LETTER OMICRON
+	'ρ': 'p', // GREEK SMALL LETTER RHO
+	'τ': 't', // GREEK SMALL LETTER TAU
+	'υ': 'u', // GREEK SMALL LETTER UPSILON
+	'а': 'a', // CYRILLIC SMALL LETTER A
+	'в': 'b', // CYRILLIC SMALL LETTER VE
+	'е': 'e', // CYRILLIC SMALL LETTER IE
+	'к': 'k', // CYRILLIC SMALL LETTER KA
+	'о': 'o', // CYRILLIC SMALL LETTER O
+	'р': 'p', // CYRILLIC SMALL LETTER ER
+	'с': 'c', // CYRILLIC SMALL LETTER ES
+	'у': 'y', // CYRILLIC SMALL LETTER U
+	'х': 'x', // CYRILLIC SMALL LETTER HA
+	'ѕ': 's', // CYRILLIC SMALL LETTER DZE
+	'і': 'i', // CYRILLIC SMALL LETTER BYELORUSSIAN-UKRAINIAN I
+}
+
+// leetspeak maps numbers and symbols to the letters that they are commonly used in place of.
+var leetspeak = map[rune]rune{
+	'0': 'o',
+	'1': 'i',
+	'3': 'e',
+	'4': 'a',
+	'5': 's',
+	'7': 't',
+	'@': 'a',
+	'$': 's',
+	'!': 'i',
+	'|': 'l',
+	'+': 't',
+}
@@ -0,0 +1,512 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test_ContainsProfanity_Evasion runs unit tests for common attempts to evade the profanity check.
+func Test_ContainsProfanity_Evasion(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{name: "plain", input: "fuck", want: true},
+		{name: "upper case", input: "FUCK", want: true},
+		{name: "embedded", input: "xXfuckXx", want: true},
+		{name: "dots", input: "f.u.c.k", want: true},
+		{name: "spaces", input: "f u c k", want: true},
+		{name: "underscores", input: "f_u_c_k", want: true},
+		{name: "mixed separators", input: "s-h.i_t", want: true},
+		{name: "greek mu", input: "fμck", want: true},
+		{name: "micro sign", input: "fµck", want: true},
+		{name: "cyrillic", input: "сunt", want: true},
+		{name: "full-width", input: "ｆｕｃｋ", want: true},
+		{name: "full-width upper case", input: "ＦＵＣＫ", want: true},
+		{name: "leet five", input: "5hit", want: true},
+		{name: "leet one", input: "sh1t", want: true},
+		{name: "leet dollar", input: "$hit", want: true},
+		{name: "leet zero", input: "m0therfucker", want: true},
+		{name: "leet with separators", input: "p.1.$.$", want: true},
+		{name: "repeated letters", input: "fuuuuuck", want: true},
+		{name: "repeated letters and separators", input: "sh i i i t", want: true},
+		{name: "hiragana", input: "ちんこ", want: true},
+		{name: "katakana", input: "チンコ", want: true},
+		{name: "half-width katakana", input: "ﾁﾝｺ", want: true},
+		{name: "kana with spaces", input: "ち ん こ", want: true},
+		{name: "kana with full-width spaces", input: "チ　ン　コ", want: true},
+		{name: "katakana word in hiragana", input: "ふぁっく", want: true},
+		{name: "kanji", input: "変態さん", want: true},
+		{name: "clean latin", input: "Rean", want: false},
+		{name: "clean latin with numbers", input: "player123", want: false},
+		{name: "clean hiragana", input: "ありがとう", want: false},
+		{name: "clean katakana", input: "ブレード", want: false},
+		{name: "clean full-width", input: "Ｂｌａｄｅ", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := ContainsProfanity(tt.input); got != tt.want {
+				t.Errorf("ContainsProfanity(%q) = %v, want %v (normalized %q, compacted %q)", tt.input, got, tt.want, normalize(tt.input), compact(normalize(tt.input)))
+			}
+		})
+	}
+}
+
+// Test_ContainsProfanity_Modes runs unit tests for match modes, severities and the allowlist, ensuring that
+// known-good words which contain short terms are not matched.
+func Test_ContainsProfanity_Modes(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantProfane  bool
+		wantTerm     string
+		wantSeverity Severity
+	}{
+		{name: "smith", input: "Smith", wantProfane: false},
+		{name: "smith with leet", input: "5mith", wantProfane: false},
+		{name: "asmodeus", input: "Asmodeus", wantProfane: false},
+		{name: "maxxie", input: "Maxxie", wantProfane: false},
+		{name: "scunthorpe", input: "Scunthorpe", wantProfane: false},
+		{name: "grape", input: "GrapeJuice", wantProfane: false},
+		{name: "deep", input: "deep", wantProfane: false},
+		{name: "hookshot", input: "フックショット", wantProfane: false},
+		{name: "homo sapiens", input: "ホモサピエンス", wantProfane: false},
+		{name: "smooth", input: "smooth", wantProfane: false},
+		{name: "sm whole word", input: "sm club", wantProfane: true, wantTerm: "sm", wantSeverity: Block},
+		{name: "sm separated word", input: "mr.sm", wantProfane: true, wantTerm: "sm", wantSeverity: Block},
+		{name: "xx whole word", input: "xx", wantProfane: true, wantTerm: "xx", wantSeverity: Block},
+		{name: "3p whole word", input: "3p", wantProfane: true, wantTerm: "3p", wantSeverity: Block},
+		{name: "3p in word", input: "r3play", wantProfane: false},
+		{name: "smith and sm", input: "Smith sm fan", wantProfane: true, wantTerm: "sm", wantSeverity: Block},
+		{name: "allowlist does not hide other terms", input: "Scunthorpe cunt", wantProfane: true, wantTerm: "cunt", wantSeverity: Block},
+		{name: "term inside allowed word", input: "trapeze", wantProfane: false},
+		{name: "term after allowed word", input: "grapefuck", wantProfane: true, wantTerm: "fuck", wantSeverity: AllowInPrivate},
+		{name: "term straddles end of allowed word", input: "cosmicunt", wantProfane: true, wantTerm: "cunt", wantSeverity: Block},
+		{name: "term straddles start of allowed word", input: "cuntrapeze", wantProfane: true, wantTerm: "cunt", wantSeverity: Block},
+		{name: "term straddles allowed word with separators", input: "c.o.s.m.i.c.u.n.t", wantProfane: true, wantTerm: "cunt", wantSeverity: Block},
+		{name: "hook whole word is flagged", input: "フック", wantProfane: true, wantTerm: "フック", wantSeverity: Flag},
+		{name: "mild swearing is allowed in private", input: "shit", wantProfane: true, wantTerm: "shit", wantSeverity: AllowInPrivate},
+		{name: "most severe match wins", input: "shitcunt", wantProfane: true, wantTerm: "cunt", wantSeverity: Block},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotProfane, gotMatch := ContainsProfanity(tt.input)
+			if gotProfane != tt.wantProfane {
+				t.Fatalf("ContainsProfanity(%q) profane = %v, want %v (matched %q: %v)", tt.input, gotProfane, tt.wantProfane, gotMatch.Term, gotMatch.Reason)
+			}
+			if !gotProfane {
+				return
+			}
+			if gotMatch.Term != tt.wantTerm {
+				t.Errorf("ContainsProfanity(%q) term = %q, want %q", tt.input, gotMatch.Term, tt.wantTerm)
+			}
+			if gotMatch.Severity != tt.wantSeverity {
+				t.Errorf("ContainsProfanity(%q) severity = %v, want %v", tt.input, gotMatch.Severity, tt.wantSeverity)
+			}
+			if gotMatch.Reason == "" {
+				t.Errorf("ContainsProfanity(%q) returned an empty reason", tt.input)
+			}
+		})
+	}
+}
+
+// Test_normalize runs unit tests for the normalization pipeline.
+func Test_normalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantNormal    string
+		wantCompacted string
+	}{
+		{name: "full-width", input: "ＡＢＣ１２３", wantNormal: "abc123", wantCompacted: "abci2e"},
+		{name: "half-width katakana", input: "ｶｰﾄﾞ", wantNormal: "かーど", wantCompacted: "かーど"},
+		{name: "katakana", input: "カタカナ", wantNormal: "かたかな", wantCompacted: "かたかな"},
+		{name: "separators", input: "a b.c-d_e・f", wantNormal: "a b.c-d_e・f", wantCompacted: "abcdef"},
+		{name: "leet", input: "h4x0r", wantNormal: "h4x0r", wantCompacted: "haxor"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotNormal := normalize(tt.input)
+			if gotNormal != tt.wantNormal {
+				t.Errorf("normalize(%q) = %q, want %q", tt.input, gotNormal, tt.wantNormal)
+			}
+			if gotCompacted := compact(gotNormal); gotCompacted != tt.wantCompacted {
+				t.Errorf("compact(%q) = %q, want %q", gotNormal, gotCompacted, tt.wantCompacted)
+			}
+		})
+	}
+}
+
+// Test_Censor runs unit tests for masking matches, ensuring that spans are reported in runes of the original input, even
+// when normalization changes the length of the input.
+func Test_Censor(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		options      CensorOptions
+		wantCensored string
+		wantSpans    [][2]int
+	}{
+		{name: "clean", input: "good game", options: DefaultCensorOptions, wantCensored: "good game"},
+		{name: "plain", input: "oh fuck off", options: DefaultCensorOptions, wantCensored: "oh **** off", wantSpans: [][2]int{{3, 7}}},
+		{name: "keep first", input: "oh fuck off", options: CensorOptions{KeepFirst: true, Severity: AllowInPrivate}, wantCensored: "oh f*** off", wantSpans: [][2]int{{3, 7}}},
+		{name: "keep first and last", input: "fuuuck", options: CensorOptions{Mask: '#', KeepFirst: true, KeepLast: true}, wantCensored: "f####k", wantSpans: [][2]int{{0, 6}}},
+		{name: "separators are kept", input: "f.u.c.k", options: DefaultCensorOptions, wantCensored: "*.*.*.*", wantSpans: [][2]int{{0, 7}}},
+		{name: "leet is masked", input: "$hit", options: DefaultCensorOptions, wantCensored: "****", wantSpans: [][2]int{{0, 4}}},
+		{name: "full-width", input: "ｇｇ ｆｕｃｋ", options: DefaultCensorOptions, wantCensored: "ｇｇ ****", wantSpans: [][2]int{{3, 7}}},
+		{name: "half-width katakana", input: "ｵﾅﾆｰ!", options: DefaultCensorOptions, wantCensored: "****!", wantSpans: [][2]int{{0, 4}}},
+		{name: "whole word", input: "mr.sm", options: DefaultCensorOptions, wantCensored: "mr.**", wantSpans: [][2]int{{3, 5}}},
+		{name: "allowlist", input: "Smith", options: DefaultCensorOptions, wantCensored: "Smith"},
+		{name: "below severity is reported", input: "shit", options: CensorOptions{Severity: Block}, wantCensored: "shit", wantSpans: [][2]int{{0, 4}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCensored, gotSpans := Censor(tt.input, tt.options)
+			if gotCensored != tt.wantCensored {
+				t.Errorf("Censor(%q) = %q, want %q", tt.input, gotCensored, tt.wantCensored)
+			}
+
+			ranges := make([][2]int, 0, len(gotSpans))
+			for _, span := range gotSpans {
+				if len(ranges) == 0 || ranges[len(ranges)-1] != [2]int{span.Start, span.End} {
+					ranges = append(ranges, [2]int{span.Start, span.End})
+				}
+			}
+
+			if fmt.Sprint(ranges) != fmt.Sprint(append([][2]int{}, tt.wantSpans...)) {
+				t.Errorf("Censor(%q) spans = %v, want %v", tt.input, ranges, tt.wantSpans)
+			}
+		})
+	}
+}
+
+// Test_ParseList runs unit tests for parsing word lists.
+func Test_ParseList(t *testing.T) {
+	tests := []struct {
+		name          string
+		input         string
+		wantEntries   []Entry
+		wantAllowlist []string
+		wantErr       bool
+	}{
+		{
+			name:        "terms",
+			input:       "# comment\n\nfuck\tsubstring\tprivate\nsm\tword\tblock\n",
+			wantEntries: []Entry{{Term: "fuck", Mode: Substring, Severity: AllowInPrivate, Locale: "en"}, {Term: "sm", Mode: WholeWord, Severity: Block, Locale: "en"}},
+		},
+		{
+			name:          "allowlist",
+			input:         "smith\tallow\n",
+			wantAllowlist: []string{"smith"},
+		},
+		{name: "missing mode", input: "fuck\n", wantErr: true},
+		{name: "unknown mode", input: "fuck\tsometimes\tblock\n", wantErr: true},
+		{name: "unknown severity", input: "fuck\tsubstring\tvery\n", wantErr: true},
+		{name: "too many fields", input: "fuck\tsubstring\tblock\textra\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseList("en", strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if fmt.Sprint(got.Entries) != fmt.Sprint(tt.wantEntries) {
+				t.Errorf("ParseList() entries = %v, want %v", got.Entries, tt.wantEntries)
+			}
+			if fmt.Sprint(got.Allowlist) != fmt.Sprint(tt.wantAllowlist) {
+				t.Errorf("ParseList() allowlist = %v, want %v", got.Allowlist, tt.wantAllowlist)
+			}
+		})
+	}
+}
+
+// Test_Filter_Reload ensures that a filter picks up changes to its sources once its time to live has passed, and keeps
+// its current dictionary if a source fails to load.
+func Test_Filter_Reload(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "en.txt")
+	if err := os.WriteFile(filename, []byte("bladeword\tsubstring\tblock\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	filter, err := NewFilter(time.Millisecond, File(filename))
+	if err != nil {
+		t.Fatalf("NewFilter() error = %v", err)
+	}
+
+	if profane, _ := filter.Dictionary().ContainsProfanity("my bladeword"); !profane {
+		t.Fatalf("ContainsProfanity() did not match a term from the file")
+	}
+
+	// Change the file, and wait for the dictionary to be reloaded in the background.
+	if err := os.WriteFile(filename, []byte("otherword\tsubstring\tblock\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(time.Millisecond * 2)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if profane, _ := filter.Dictionary().ContainsProfanity("my otherword"); profane {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("Dictionary() was not reloaded")
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+
+	// Remove the file, and ensure that the current dictionary is kept.
+	if err := os.Remove(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := filter.Reload(); err == nil {
+		t.Fatalf("Reload() did not return an error for a missing file")
+	}
+
+	if profane, _ := filter.Dictionary().ContainsProfanity("my otherword"); !profane {
+		t.Fatalf("Reload() discarded the current dictionary after an error")
+	}
+}
+
+// Test_Dictionary_RegexBaseline ensures that the automaton gives the same results as matching each term with its own
+// regex, which is how terms were matched before the automaton was introduced.
+func Test_Dictionary_RegexBaseline(t *testing.T) {
+	list, err := Embedded().Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dictionary := Compile(list)
+	baseline := compileBaseline(list)
+	for _, input := range append(benchmarkInputs, strings.Split(benchmarkChat, " ")...) {
+		wantProfane, wantMatch := baseline.containsProfanity(input)
+		gotProfane, gotMatch := dictionary.ContainsProfanity(input)
+		if gotProfane != wantProfane || gotMatch.Severity != wantMatch.Severity {
+			t.Errorf("ContainsProfanity(%q) = %v %v (%q), baseline = %v %v (%q)", input, gotProfane, gotMatch.Severity, gotMatch.Term, wantProfane, wantMatch.Severity, wantMatch.Term)
+		}
+	}
+}
+
+// benchmarkInputs are handle length inputs, both clean and profane, used for benchmarks.
+var benchmarkInputs = []string{
+	"Rean", "player123", "ありがとう", "ブレード", "Ｂｌａｄｅ", "Smith", "Scunthorpe", "フックショット", "smooth", "r3play",
+	"fuck", "f.u.c.k", "ｆｕｃｋ", "5hit", "sh i i i t", "ちんこ", "ﾁﾝｺ", "変態さん", "sm club", "xx", "3p", "shitcunt",
+	"cosmicunt", "cuntrapeze", "grapefuck", "trapeze",
+}
+
+// benchmarkChat is a chat length input, used for benchmarks.
+var benchmarkChat = "gg that was a close one, I really thought the bolt card would turn it around but your mirror was perfect. " +
+	"rematch? also ありがとうございました、また遊びましょう！ hookshot フックショット is my favourite card lol"
+
+// Benchmark_Automaton_Handles benchmarks the automaton with handle length inputs.
+func Benchmark_Automaton_Handles(b *testing.B) {
+	dictionary := Compile(mustLoad(b, Embedded()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dictionary.ContainsProfanity(benchmarkInputs[i%len(benchmarkInputs)])
+	}
+}
+
+// Benchmark_RegexLoop_Handles benchmarks the regex loop with handle length inputs.
+func Benchmark_RegexLoop_Handles(b *testing.B) {
+	baseline := compileBaseline(mustLoad(b, Embedded()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		baseline.containsProfanity(benchmarkInputs[i%len(benchmarkInputs)])
+	}
+}
+
+// Benchmark_Automaton_Chat benchmarks the automaton with a chat length input.
+func Benchmark_Automaton_Chat(b *testing.B) {
+	dictionary := Compile(mustLoad(b, Embedded()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		dictionary.ContainsProfanity(benchmarkChat)
+	}
+}
+
+// Benchmark_RegexLoop_Chat benchmarks the regex loop with a chat length input.
+func Benchmark_RegexLoop_Chat(b *testing.B) {
+	baseline := compileBaseline(mustLoad(b, Embedded()))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		baseline.containsProfanity(benchmarkChat)
+	}
+}
+
+// Benchmark_Compile benchmarks compiling the embedded lists, which happens on each reload.
+func Benchmark_Compile(b *testing.B) {
+	list := mustLoad(b, Embedded())
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		Compile(list)
+	}
+}
+
+// mustLoad loads the list from the specified source, failing the benchmark on error.
+func mustLoad(b *testing.B, source Source) List {
+	list, err := source.Load()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return list
+}
+
+// baseline matches each term with its own regex, one at a time, which is how terms were matched before the automaton
+// was introduced. It is kept for comparison in tests and benchmarks.
+type baseline struct {
+	terms   []baselineTerm
+	allowed []*regexp.Regexp
+}
+
+// baselineTerm is a single entry, compiled into the patterns used to match it against normalized and compacted input.
+type baselineTerm struct {
+	entry      Entry
+	normalized *regexp.Regexp
+	compact    *regexp.Regexp
+}
+
+// compileBaseline compiles each of the entries in the list into a term, and each allowed word into a pattern.
+func compileBaseline(list List) (b baseline) {
+	for _, entry := range list.Entries {
+		normalized := repeatablePattern(normalize(entry.Term))
+		compacted := repeatablePattern(stripSeparators(normalize(entry.Term)))
+		if entry.Mode != Substring {
+			normalized = "^" + normalized + "$"
+			compacted = "^" + compacted + "$"
+		}
+
+		b.terms = append(b.terms, baselineTerm{
+			entry:      entry,
+			normalized: regexp.MustCompile(normalized),
+			compact:    regexp.MustCompile(compacted),
+		})
+	}
+
+	for _, word := range list.Allowlist {
+		b.allowed = append(b.allowed, regexp.MustCompile(repeatablePattern(stripSeparators(normalize(word)))))
+	}
+
+	return b
+}
+
+// containsProfanity checks each term against the input, keeping the most severe match.
+func (b baseline) containsProfanity(input string) (profane bool, match Match) {
+	normalized := normalize(input)
+	compacted := compact(normalized)
+	for _, t := range b.terms {
+		if profane && t.entry.Severity <= match.Severity {
+			continue
+		}
+
+		if b.match(t, normalized, compacted) {
+			profane = true
+			match = Match{Entry: t.entry, Reason: reason(t.entry)}
+			if match.Severity == Block {
+				break
+			}
+		}
+	}
+
+	return profane, match
+}
+
+// match returns true if the term matches the input outside of an allowed word, depending on the match mode for the term.
+func (b baseline) match(t baselineTerm, normalized string, compacted string) bool {
+	switch t.entry.Mode {
+	case Substring:
+		return b.matchOutsideAllowed(t.normalized, normalized) || b.matchOutsideAllowed(t.compact, compacted)
+	case WholeWord:
+		for _, span := range wordSpans(normalized) {
+			word := normalized[span[0]:span[1]]
+			if b.allowedAt(normalized, span[0], span[1]) {
+				continue
+			}
+
+			if t.normalized.MatchString(word) || (t.compact.MatchString(compact(word)) && !b.allowedAt(compact(word), 0, len(compact(word)))) {
+				return true
+			}
+		}
+	case Exact:
+		trimmed := strings.TrimSpace(compacted)
+		return (t.normalized.MatchString(normalized) && !b.allowedAt(normalized, 0, len(normalized))) || (t.compact.MatchString(trimmed) && !b.allowedAt(trimmed, 0, len(trimmed)))
+	}
+
+	return false
+}
+
+// matchOutsideAllowed returns true if the pattern matches the input anywhere that is not entirely within an allowed word.
+func (b baseline) matchOutsideAllowed(pattern *regexp.Regexp, input string) bool {
+	for _, loc := range pattern.FindAllStringIndex(input, -1) {
+		if !b.allowedAt(input, loc[0], loc[1]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowedAt returns true if the specified range of bytes in the input is entirely within an allowed word.
+func (b baseline) allowedAt(input string, start int, end int) bool {
+	for _, pattern := range b.allowed {
+		for _, loc := range pattern.FindAllStringIndex(input, -1) {
+			if loc[0] <= start && end <= loc[1] {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// wordSpans returns the range of bytes covered by each word in the normalized input, separated by whitespace,
+// punctuation and symbols. Characters that are used in leetspeak (such as "$") are not treated as separators, so that
+// "$hit" is still a single word.
+func wordSpans(normalized string) (spans [][2]int) {
+	start := -1
+	for i, r := range normalized {
+		_, leet := leetspeak[r]
+		separator := isSeparator(r) && !leet
+		if separator && start >= 0 {
+			spans = append(spans, [2]int{start, i})
+			start = -1
+		} else if !separator && start < 0 {
+			start = i
+		}
+	}
+
+	if start >= 0 {
+		spans = append(spans, [2]int{start, len(normalized)})
+	}
+
+	return spans
+}
+
+// repeatablePattern returns a regex pattern that matches the input literally, but with each character allowed to be
+// repeated any number of times, so that "fuck" also matches "fuuuuck".
+func repeatablePattern(input string) string {
+	var builder strings.Builder
+	for _, r := range input {
+		builder.WriteString(regexp.QuoteMeta(string(r)))
+		builder.WriteRune('+')
+	}
+
+	return builder.String()
+}
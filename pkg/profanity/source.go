@@ -0,0 +1,164 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// lists contains the default word lists, one file per locale, named after the locale (such as "en.txt").
+//
+//go:embed lists/*.txt
+var lists embed.FS
+
+// allowMode is the match mode used in word lists for words in the allowlist.
+const allowMode = "allow"
+
+// List is a word list, containing terms to match and known-good words to ignore.
+type List struct {
+	Entries []Entry
+
+	// Allowlist contains known-good words that contain terms from a word list. Matches that fall entirely within an
+	// allowed word are ignored, so that (for example) "Scunthorpe" is not matched by "cunt", while terms that only
+	// overlap an allowed word are still matched.
+	Allowlist []string
+}
+
+// Add adds a term to the list, using the names for the match mode and severity as used in word list files. Terms with
+// the match mode "allow" are added to the allowlist, and do not have a severity.
+func (l *List) Add(locale string, term string, mode string, severity string) (err error) {
+	if term == "" {
+		return fmt.Errorf("Term is empty")
+	}
+
+	if mode == allowMode {
+		l.Allowlist = append(l.Allowlist, term)
+		return nil
+	}
+
+	entry := Entry{Term: term, Locale: locale}
+
+	entry.Mode, err = parseMatchMode(mode)
+	if err != nil {
+		return err
+	}
+
+	entry.Severity, err = parseSeverity(severity)
+	if err != nil {
+		return err
+	}
+
+	l.Entries = append(l.Entries, entry)
+	return nil
+}
+
+// ParseList reads a word list for the specified locale. Each line contains a term, match mode and severity, separated
+// by tabs. Empty lines, and lines starting with "#", are ignored.
+func ParseList(locale string, reader io.Reader) (list List, err error) {
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		// Allowlist lines have no severity, so it is left empty for them.
+		fields := strings.Split(text, "\t")
+		if len(fields) < 2 || len(fields) > 3 {
+			return list, fmt.Errorf("Line %v: expected a term, match mode and severity", line)
+		}
+
+		severity := ""
+		if len(fields) == 3 {
+			severity = strings.TrimSpace(fields[2])
+		}
+
+		err = list.Add(locale, strings.TrimSpace(fields[0]), strings.TrimSpace(fields[1]), severity)
+		if err != nil {
+			return list, fmt.Errorf("Line %v: %v", line, err)
+		}
+	}
+
+	return list, scanner.Err()
+}
+
+// Source is a source of word lists, such as the embedded defaults, a file, or a database table.
+type Source interface {
+	Load() (List, error)
+}
+
+// SourceFunc is an adapter that allows a function to be used as a source.
+type SourceFunc func() (List, error)
+
+// Load calls f.
+func (f SourceFunc) Load() (List, error) {
+	return f()
+}
+
+// Embedded returns a source for the embedded default word lists for the specified locales, or every locale if none
+// are specified.
+func Embedded(locales ...string) Source {
+	return SourceFunc(func() (list List, err error) {
+
+		// If no locales were specified, use every embedded list.
+		if len(locales) == 0 {
+			files, err := lists.ReadDir("lists")
+			if err != nil {
+				return list, err
+			}
+
+			for _, file := range files {
+				locales = append(locales, strings.TrimSuffix(file.Name(), path.Ext(file.Name())))
+			}
+		}
+
+		// Parse the list for each locale, and merge them.
+		for _, locale := range locales {
+			file, err := lists.Open(path.Join("lists", locale+".txt"))
+			if err != nil {
+				return list, err
+			}
+
+			parsed, err := ParseList(locale, file)
+			file.Close()
+			if err != nil {
+				return list, fmt.Errorf("Embedded list %v: %v", locale, err)
+			}
+
+			list.Entries = append(list.Entries, parsed.Entries...)
+			list.Allowlist = append(list.Allowlist, parsed.Allowlist...)
+		}
+
+		return list, nil
+	})
+}
+
+// File returns a source for the word list in the specified file, which is in the same format as the embedded lists. The
+// locale is taken from the name of the file, so "/etc/blade-ii/ja.txt" is a japanese list. If the path is empty, the
+// source is also empty, so that an optional path (such as one from an environment variable) can be used directly.
+func File(filename string) Source {
+	return SourceFunc(func() (list List, err error) {
+		if filename == "" {
+			return list, nil
+		}
+
+		file, err := os.Open(filename)
+		if err != nil {
+			return list, err
+		}
+
+		defer file.Close()
+
+		return ParseList(strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)), file)
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2020 James Einosuke Stanton. All rights reserved.
+// Use of this source code is governed by the MIT license
+// that can be found in the LICENSE.md file.
+
+// Package profanity implements very basic profanity checking.
+package profanity
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// text is a normalized form of an input, along with the range of runes in the original input that each rune came from,
+// so that matches can be mapped back to the original input. Normalization can change the number of runes (such as "ｶﾞ",
+// which is two runes, becoming "が"), so every rune produced from a part of the input covers that entire part.
+type text struct {
+	runes []rune
+	start []int
+	end   []int
+}
+
+// normalizeText returns the normalized form of the input (see normalize), keeping track of where each rune came from.
+func normalizeText(input string) (t text) {
+
+	// Normalize the input one segment at a time. Segments are split at normalization boundaries, so normalizing each
+	// segment separately gives the same result as normalizing the entire input.
+	offset := 0
+	for len(input) > 0 {
+		n := norm.NFKC.NextBoundaryInString(input, true)
+		if n <= 0 {
+			n = len(input)
+		}
+
+		segment := input[:n]
+		input = input[n:]
+
+		length := utf8.RuneCountInString(segment)
+		for _, r := range norm.NFKC.String(segment) {
+			t.append(foldRune(unicode.ToLower(r)), offset, offset+length)
+		}
+
+		offset += length
+	}
+
+	return t
+}
+
+// append adds a rune to the text, which came from the specified range of runes in the original input.
+func (t *text) append(r rune, start int, end int) {
+	t.runes = append(t.runes, r)
+	t.start = append(t.start, start)
+	t.end = append(t.end, end)
+}
+
+// span returns the range of runes in the original input that the specified range of runes in the text came from.
+func (t text) span(start int, end int) (inputStart int, inputEnd int) {
+	return t.start[start], t.end[end-1]
+}
+
+// compact returns the compacted form of the text (see compact).
+func (t text) compact() (compacted text) {
+	for i, r := range t.runes {
+		r = leetRune(r)
+		if !isSeparator(r) {
+			compacted.append(r, t.start[i], t.end[i])
+		}
+	}
+
+	return compacted
+}
+
+// words splits the text into words (see splitWords).
+func (t text) words() (words []text) {
+	var word text
+	for i, r := range t.runes {
+		if _, leet := leetspeak[r]; isSeparator(r) && !leet {
+			if len(word.runes) > 0 {
+				words = append(words, word)
+				word = text{}
+			}
+
+			continue
+		}
+
+		word.append(r, t.start[i], t.end[i])
+	}
+
+	if len(word.runes) > 0 {
+		words = append(words, word)
+	}
+
+	return words
+}
+
+// trimSpace returns the text with leading and trailing whitespace removed.
+func (t text) trimSpace() text {
+	start, end := 0, len(t.runes)
+	for start < end && unicode.IsSpace(t.runes[start]) {
+		start++
+	}
+
+	for end > start && unicode.IsSpace(t.runes[end-1]) {
+		end--
+	}
+
+	return text{runes: t.runes[start:end], start: t.start[start:end], end: t.end[start:end]}
+}
+
+// String returns the text as a string.
+func (t text) String() string {
+	return string(t.runes)
+}